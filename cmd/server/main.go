@@ -1,36 +1,178 @@
 package main
 
 import (
+    "context"
+    "fmt"
     "log"
-    "os"
+    "net"
+    "net/http"
+    "path/filepath"
+    "time"
 
+    apihandlers "collabhub-music-backend/internal/api/handlers"
+    apimiddleware "collabhub-music-backend/internal/api/middleware"
+    "collabhub-music-backend/internal/config"
+    "collabhub-music-backend/internal/database"
     "collabhub-music-backend/internal/handlers"
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/repository"
     "collabhub-music-backend/internal/services"
+    apiutils "collabhub-music-backend/internal/utils"
 
     "github.com/gin-gonic/gin"
 )
 
 func main() {
-    // Create upload directories
-    uploadPath := "uploads"
-    zipUploadPath := "uploads/zips"
-    extractPath := "uploads/extracted"
-    
-    os.MkdirAll(zipUploadPath, 0755)
-    os.MkdirAll(extractPath, 0755)
+    // Load configuration
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatal("Failed to load configuration:", err)
+    }
+
+    // Connect to the database
+    db, err := database.InitDB(cfg.Database.DSN())
+    if err != nil {
+        log.Fatal("Failed to connect to database:", err)
+    }
+    if err := database.RunMigrations(db); err != nil {
+        log.Fatal("Failed to run migrations:", err)
+    }
+
+    // Archives and extracted projects live under the configured upload path, which may be
+    // a mounted volume; the directories are created and checked once the services exist
+    uploadPath := cfg.Storage.UploadPath
+    extractPath := filepath.Join(uploadPath, "extracted")
+
+    // Every list endpoint clamps its page size to the configured maximum
+    apiutils.SetMaxPageSize(cfg.Server.MaxPageSize)
 
     // Create Gin router
-    r := gin.Default()
+    r := gin.New()
+    r.Use(middleware.RequestID(), middleware.RequestLogger(cfg.Logging.RedactFields), middleware.Recovery())
+
+    // Browser clients may only call the API from the configured origins; preflight
+    // requests are answered before authentication
+    r.Use(middleware.CORSMiddleware(&cfg.CORS))
     
     // Set max form size (500MB for file uploads)
     r.MaxMultipartMemory = 500 << 20 // 500MB
 
+    storageLayout, err := services.ParseStorageLayout(cfg.Storage.Layout)
+    if err != nil {
+        log.Fatal("Invalid storage configuration:", err)
+    }
+
     // Create services
-    zipService := services.NewZipService(uploadPath, extractPath)
+    zipService := services.NewZipServiceWithConfig(services.ZipServiceConfig{
+        UploadPath:               uploadPath,
+        ExtractPath:              extractPath,
+        Layout:                   storageLayout,
+        MaxEntries:               cfg.Storage.MaxZipEntries,
+        MaxPathDepth:             cfg.Storage.MaxPathDepth,
+        MaxPathLength:            cfg.Storage.MaxPathLength,
+        AllowSymlinks:            cfg.Storage.AllowSymlinks,
+        SkipHidden:               cfg.Storage.SkipHidden,
+        VerifyCRC:                cfg.Storage.VerifyCRC,
+        MaxConcurrentExtractions: cfg.Storage.MaxExtractions,
+        ExtractionQueueTimeout:   time.Duration(cfg.Storage.ExtractionWait) * time.Second,
+        MaxAudioFilesPerProject:  cfg.Storage.MaxAudioFiles,
+        Uploads:                  repository.NewFileUploadRepository(db),
+        Projects:                 repository.NewProjectRepository(db),
+        Branches:                 repository.NewBranchRepository(db),
+        Files:                    repository.NewFileRepository(db),
+        Tracks:                   repository.NewTrackRepository(db),
+        Transactor:               repository.NewTransactor(db),
+    })
+    if err := zipService.CheckStorage(); err != nil {
+        log.Fatal("Storage is not usable:", err)
+    }
+
+    fileService := services.NewFileServiceWithConfig(services.FileServiceConfig{
+        Files:       repository.NewFileRepository(db),
+        ExtractPath: extractPath,
+        Layout:      storageLayout,
+        Branches:    repository.NewBranchRepository(db),
+        Projects:    repository.NewProjectRepository(db),
+        Tracks:      repository.NewTrackRepository(db),
+    })
+    keycloakService := services.NewKeycloakService(cfg.Keycloak.URL, cfg.Keycloak.Realm, cfg.Keycloak.ClientID, cfg.Keycloak.ClientSecret)
+    userService := services.NewUserService(repository.NewUserRepository(db), keycloakService)
+    // Collaborators are emailed about invitations and ownership changes when email is
+    // enabled; otherwise the project service sends nothing
+    var notifier services.Notifier
+    if cfg.Email.Enabled {
+        notifier = services.NewSMTPNotifier(cfg.Email.SMTPHost, cfg.Email.SMTPPort, cfg.Email.Username, cfg.Email.Password, cfg.Email.FromName, cfg.Email.FromAddress)
+    }
+    projectService := services.NewProjectService(
+        repository.NewProjectRepository(db),
+        repository.NewOrganizationRepository(db),
+        repository.NewUserRepository(db),
+        repository.NewBranchRepository(db),
+        repository.NewFileRepository(db),
+        notifier,
+    )
+    if err := projectService.SetDefaultCollaboratorRole(cfg.Projects.DefaultCollaboratorRole); err != nil {
+        log.Fatal("Invalid project configuration:", err)
+    }
+    organizationService := services.NewOrganizationService(
+        repository.NewOrganizationRepository(db),
+        repository.NewUserRepository(db),
+        repository.NewProjectRepository(db),
+    )
+
+    // Protected routes validate tokens against Keycloak; the mock middleware in
+    // internal/middleware is only for tests and refuses to run in production
+    authMiddleware := apimiddleware.NewAuthMiddleware(
+        apimiddleware.NewJWTMiddleware(cfg.Keycloak.URL, cfg.Keycloak.Realm),
+        keycloakService,
+        userService,
+    )
 
     // Create handlers
-    authHandler := handlers.NewAuthHandler()
-    zipHandler := handlers.NewZipHandler(zipService)
+    authHandler := handlers.NewAuthHandler(userService)
+    webhookSender := services.NewWebhookSenderWithConfig(services.WebhookSenderConfig{
+        Projects:             repository.NewProjectRepository(db),
+        Secret:               cfg.Webhooks.Secret,
+        Timeout:              time.Duration(cfg.Webhooks.Timeout) * time.Second,
+        AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+    })
+    jobManager := services.NewJobManagerWithConfig(services.JobManagerConfig{
+        ZipService: zipService,
+        Webhooks:   webhookSender,
+        JobTTL:     time.Duration(cfg.Storage.JobRetention) * time.Hour,
+    })
+    go jobManager.RunJanitor(context.Background(), time.Minute)
+    zipHandler := handlers.NewZipHandler(zipService, jobManager, cfg.Storage.MaxZipUploadSize)
+    fileHandler := handlers.NewFileHandler(fileService)
+    jobHandler := handlers.NewJobHandler(jobManager)
+    adminHandler := handlers.NewAdminHandler(zipService)
+    projectHandler := apihandlers.NewProjectHandler(projectService)
+    organizationHandler := apihandlers.NewOrganizationHandler(
+        organizationService,
+        services.NewPolicyService(repository.NewProjectRepository(db), repository.NewOrganizationRepository(db)),
+    )
+    userHandler := apihandlers.NewUserHandler(userService)
+    commentHandler := apihandlers.NewCommentHandler(services.NewCommentService(
+        repository.NewCommentRepository(db),
+        repository.NewProjectRepository(db),
+        repository.NewFileRepository(db),
+        repository.NewTrackRepository(db),
+    ))
+    uploadLimiter := middleware.NewConcurrencyLimiter(cfg.Storage.UploadsPerUser)
+    validationLimiter := middleware.NewRateLimiter(cfg.Storage.ValidationLimit, time.Minute)
+    healthHandler := handlers.NewHealthHandler(map[string]handlers.HealthCheck{
+        "database": func(ctx context.Context) error {
+            return database.Ping(ctx, db)
+        },
+        "keycloak": keycloakService.Ping,
+    })
+
+    // Liveness and readiness probes for orchestrators
+    health := r.Group("/api/health")
+    {
+        health.GET("/live", healthHandler.Live)
+        health.GET("/ready", healthHandler.Ready)
+    }
 
     // Setup routes
     api := r.Group("/api/v1")
@@ -43,27 +185,156 @@ func main() {
             auth.POST("/logout", authHandler.Logout)
         }
 
-        // File upload and ZIP handling routes
+        // Upload formats and limits are public so clients can check them before signing in,
+        // and change rarely enough for clients and CDNs to cache them
+        publicCache := middleware.CacheControl(time.Duration(cfg.Server.CacheMaxAge) * time.Second)
+        api.GET("/files/capabilities", publicCache, zipHandler.GetCapabilities)
+
+        // File upload and ZIP handling routes; responses are per user, so never cached
         files := api.Group("/files")
+        files.Use(authMiddleware.RequireAuth(), middleware.NoStore())
         {
             // ZIP file operations
             zip := files.Group("/zip")
             {
-                zip.POST("/upload", zipHandler.UploadZip)
-                zip.GET("/:file_id/validate", zipHandler.ValidateZip)
-                zip.GET("/:file_id/info", zipHandler.GetZipInfo)
+                zip.POST("/upload", uploadLimiter.Middleware(), zipHandler.UploadZip)
+                zip.GET("/uploads", zipHandler.ListUploads)
+                zip.POST("/validate-batch", zipHandler.ValidateZipBatch)
+                zip.DELETE("/:file_id", zipHandler.DeleteZip)
+                zip.GET("/:file_id/validate", validationLimiter.Middleware(), zipHandler.ValidateZip)
+                zip.GET("/:file_id/info", validationLimiter.Middleware(), zipHandler.GetZipInfo)
                 zip.POST("/:file_id/extract", zipHandler.ExtractZip)
+                zip.POST("/:file_id/extract-entry", zipHandler.ExtractEntry)
                 zip.POST("/:file_id/project", zipHandler.CreateProjectFromZip)
             }
 
+            // Background jobs of the current user
+            files.GET("/jobs", jobHandler.ListJobs)
+            files.DELETE("/jobs/:job_id", middleware.UUIDParam("job_id"), jobHandler.CancelJob)
+            files.GET("/jobs/:job_id/events", middleware.UUIDParam("job_id"), jobHandler.JobEvents)
+
+            // Stored file operations
+            stored := files.Group("/:id", middleware.UUIDParam("id"))
+            {
+                stored.GET("/download", fileHandler.DownloadFile)
+                stored.HEAD("/download", fileHandler.DownloadFile)
+                stored.GET("/metadata", fileHandler.GetFileMetadata)
+                stored.GET("/versions/diff", fileHandler.DiffVersions)
+                stored.GET("/versions/:version/download", fileHandler.DownloadVersion)
+                stored.HEAD("/versions/:version/download", fileHandler.DownloadVersion)
+            }
+
             // Project file operations
-            projects := files.Group("/projects")
+            projects := files.Group("/projects/:project_id", middleware.UUIDParam("project_id"))
+            {
+                projects.GET("/files", zipHandler.ListExtractedFiles)
+                projects.GET("/files/info", zipHandler.GetExtractedFileInfo)
+                projects.POST("/files/metadata", zipHandler.GetFilesMetadata)
+                projects.GET("/tree", fileHandler.GetFileTree)
+                projects.POST("/metadata/bulk", fileHandler.BulkUpdateMetadata)
+                projects.POST("/reprocess", fileHandler.ReprocessProject)
+                projects.POST("/resync-files", fileHandler.ResyncProjectFiles)
+                projects.DELETE("/cleanup", zipHandler.CleanupProject)
+            }
+        }
+
+        // Directories and sign-up checks that work without signing in; the handlers rate
+        // limit the username lookups per client
+        api.GET("/projects/public", projectHandler.ListPublicProjects)
+        api.GET("/organizations/public", organizationHandler.ListPublicOrganizations)
+        api.POST("/users/register", userHandler.RegisterUser)
+        api.GET("/users/username-available", userHandler.CheckUsernameAvailable)
+        api.GET("/users/by-username/:username", userHandler.GetUserByUsername)
+
+        // Projects, their collaborators and branches; access to each project is checked
+        // by the project service
+        projects := api.Group("/projects", authMiddleware.RequireAuth(), middleware.NoStore())
+        {
+            projects.GET("", projectHandler.GetProjects)
+            projects.POST("", projectHandler.CreateProject)
+            projects.POST("/batch-delete", projectHandler.BatchDeleteProjects)
+
+            project := projects.Group("/:id", middleware.UUIDParam("id"))
             {
-                projects.GET("/:project_id/files", zipHandler.ListExtractedFiles)
-                projects.DELETE("/:project_id/cleanup", zipHandler.CleanupProject)
+                project.GET("", projectHandler.GetProject)
+                project.PUT("", projectHandler.UpdateProject)
+                project.DELETE("", projectHandler.DeleteProject)
+                project.PUT("/settings", projectHandler.UpdateProjectSettings)
+                project.GET("/activity", projectHandler.GetProjectActivity)
+                project.GET("/usage", projectHandler.GetProjectStorageUsage)
+                project.POST("/favorite", projectHandler.FavoriteProject)
+                project.DELETE("/favorite", projectHandler.UnfavoriteProject)
+                project.GET("/collaborators", projectHandler.GetCollaborators)
+                project.POST("/collaborators", projectHandler.AddCollaborator)
+                project.DELETE("/collaborators/:userId", middleware.UUIDParam("userId"), projectHandler.RemoveCollaborator)
+                project.POST("/invitations", projectHandler.InviteCollaborator)
+                project.POST("/invitations/accept", projectHandler.AcceptInvitation)
+                project.GET("/branches", projectHandler.ListBranches)
+
+                branch := project.Group("/branches/:branchId", middleware.UUIDParam("branchId"))
+                branch.GET("/files", projectHandler.GetBranchFiles)
+                branch.POST("/merge", projectHandler.MergeBranch)
+                branch.PUT("/rename", projectHandler.RenameBranch)
             }
         }
 
+        // Organizations and their members; private organizations are only visible to members
+        organizations := api.Group("/organizations", authMiddleware.RequireAuth(), middleware.NoStore())
+        {
+            organizations.GET("", organizationHandler.ListOrganizations)
+            organizations.POST("", organizationHandler.CreateOrganization)
+            organizations.GET("/user", organizationHandler.GetUserOrganizations)
+            organizations.GET("/search", organizationHandler.GetOrganizationByName)
+
+            organization := organizations.Group("/:id", middleware.UUIDParam("id"))
+            {
+                organization.GET("", organizationHandler.GetOrganization)
+                organization.PUT("", organizationHandler.UpdateOrganization)
+                organization.DELETE("", organizationHandler.DeleteOrganization)
+                organization.GET("/members", organizationHandler.GetOrganizationMembers)
+                organization.GET("/projects", organizationHandler.GetOrganizationProjects)
+                organization.POST("/users", organizationHandler.AddUserToOrganization)
+                organization.DELETE("/users/:user_id", middleware.UUIDParam("user_id"), organizationHandler.RemoveUserFromOrganization)
+            }
+        }
+
+        // The current user and other users' profiles; deleting, deactivating and
+        // reactivating other accounts is checked against the caller's realm roles
+        users := api.Group("/users", authMiddleware.RequireAuth(), middleware.NoStore())
+        {
+            users.GET("", userHandler.ListUsers)
+            users.GET("/me", userHandler.GetCurrentUser)
+            users.PUT("/me", userHandler.UpdateUserProfile)
+            users.GET("/me/favorites", projectHandler.ListFavoriteProjects)
+            users.GET("/me/summary", projectHandler.GetUserSummary)
+
+            user := users.Group("/:id", middleware.UUIDParam("id"))
+            {
+                user.GET("", userHandler.GetUserProfile)
+                user.DELETE("", userHandler.DeleteUser)
+                user.POST("/deactivate", userHandler.DeactivateUser)
+                user.POST("/reactivate", userHandler.ReactivateUser)
+            }
+        }
+
+        // Project comments; any project member can read and post, and authors and the
+        // project's owner and admins can delete
+        comments := api.Group("", authMiddleware.RequireAuth(), middleware.NoStore())
+        {
+            comments.POST("/projects/:id/comments", middleware.UUIDParam("id"), commentHandler.CreateComment)
+            comments.GET("/projects/:id/comments", middleware.UUIDParam("id"), commentHandler.GetComments)
+            comments.DELETE("/comments/:id", middleware.UUIDParam("id"), commentHandler.DeleteComment)
+        }
+
+        // Support staff operations
+        admin := api.Group("/admin", authMiddleware.RequireAuth(), authMiddleware.RequireRole("admin"), middleware.NoStore())
+        {
+            projectStorage := admin.Group("/projects/:id/storage", middleware.UUIDParam("id"))
+            projectStorage.GET("", adminHandler.GetProjectStorage)
+            projectStorage.DELETE("", adminHandler.PurgeProjectStorage)
+            admin.GET("/users", userHandler.SearchUsers)
+        }
+
         // Health check
         api.GET("/health", func(c *gin.Context) {
             c.JSON(200, gin.H{
@@ -74,11 +345,50 @@ func main() {
         })
     }
 
-    log.Println("Starting server on :8081")
+    addr := fmt.Sprintf(":%d", cfg.Server.Port)
+
     log.Println("Upload directory:", uploadPath)
     log.Println("Extract directory:", extractPath)
-    
-    if err := r.Run(":8081"); err != nil {
+
+    if err := runServer(r, addr, cfg); err != nil {
         log.Fatal("Failed to start server:", err)
     }
+}
+
+// runServer serves HTTPS when the configuration allows it and plain HTTP otherwise
+func runServer(r *gin.Engine, addr string, cfg *config.Config) error {
+    useTLS, err := cfg.UseTLS()
+    if err != nil {
+        return err
+    }
+
+    if useTLS {
+        if cfg.Server.SSLRedirectPort > 0 {
+            go serveHTTPSRedirect(cfg.Server.SSLRedirectPort, cfg.Server.Port)
+        }
+
+        log.Println("Starting HTTPS server on", addr)
+        return r.RunTLS(addr, cfg.Server.SSLCertPath, cfg.Server.SSLKeyPath)
+    }
+
+    log.Println("Starting HTTP server on", addr)
+    return r.Run(addr)
+}
+
+// serveHTTPSRedirect listens on the redirect port and sends every request to the HTTPS port
+func serveHTTPSRedirect(redirectPort, httpsPort int) {
+    redirect := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        host := req.Host
+        if h, _, err := net.SplitHostPort(req.Host); err == nil {
+            host = h
+        }
+        target := fmt.Sprintf("https://%s:%d%s", host, httpsPort, req.URL.RequestURI())
+        http.Redirect(w, req, target, http.StatusMovedPermanently)
+    })
+
+    addr := fmt.Sprintf(":%d", redirectPort)
+    log.Println("Redirecting HTTP to HTTPS on", addr)
+    if err := http.ListenAndServe(addr, redirect); err != nil {
+        log.Println("HTTP to HTTPS redirect server stopped:", err)
+    }
 }
\ No newline at end of file
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/models"
+    "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// CommentHandler handles HTTP requests for project comments
+type CommentHandler struct {
+    commentService *services.CommentService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService *services.CommentService) *CommentHandler {
+    return &CommentHandler{
+        commentService: commentService,
+    }
+}
+
+// CreateComment leaves a comment on a project
+// @Summary Comment on project
+// @Description Leave a comment on a project, optionally about one of its tracks or files and at a position in seconds. Set parent_id to reply to a comment; replies to replies join the same thread. Any project member can comment.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param comment body models.CreateCommentRequest true "Comment"
+// @Success 201 {object} utils.SuccessResponse{data=models.Comment}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req models.CreateCommentRequest
+    if !utils.BindJSON(c, &req) {
+        return
+    }
+
+    comment, err := h.commentService.CreateComment(parsedUserID, projectID, &req)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusCreated, "Comment created successfully", comment)
+}
+
+// GetComments lists the comments of a project
+// @Summary List project comments
+// @Description Get a paginated list of a project's comments and replies with their authors, oldest first. Replies carry the parent_id of the comment they answer.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of comments per page (default 20, max 100)"
+// @Param offset query int false "Number of comments to skip, used when page is not given"
+// @Success 200 {object} utils.SuccessResponse{data=models.CommentPage}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    page := utils.ParsePaginationParams(c)
+
+    comments, err := h.commentService.ListComments(parsedUserID, projectID, page.Limit, page.Offset)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Comments retrieved successfully", comments)
+}
+
+// DeleteComment deletes a comment
+// @Summary Delete comment
+// @Description Delete a comment together with its replies. Authors can delete their own comments, and the owner and admins of the project any comment on it.
+// @Tags comments
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Comment ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    commentID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    if err := h.commentService.DeleteComment(parsedUserID, commentID); err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Comment deleted successfully", nil)
+}
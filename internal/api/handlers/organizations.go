@@ -1,22 +1,25 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
-    "strconv"
 
     "github.com/gin-gonic/gin"
     "github.com/google/uuid"
     "collabhub-music-backend/internal/models"
     "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/internal/utils"
     "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/pkg/logger"
 )
 
 type OrganizationHandler struct {
     service *services.OrganizationService
+    policy  *services.PolicyService
 }
 
-func NewOrganizationHandler(service *services.OrganizationService) *OrganizationHandler {
-    return &OrganizationHandler{service: service}
+func NewOrganizationHandler(service *services.OrganizationService, policy *services.PolicyService) *OrganizationHandler {
+    return &OrganizationHandler{service: service, policy: policy}
 }
 
 // CreateOrganization handles the creation of a new organization
@@ -24,33 +27,37 @@ func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
     // Get authenticated user
     currentUserID, exists := middleware.GetCurrentUserID(c)
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
     userID, err := uuid.Parse(currentUserID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
         return
     }
 
     var org models.Organization
-    if err := c.ShouldBindJSON(&org); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+    if !utils.BindJSON(c, &org) {
         return
     }
 
     // Validation
     if org.Name == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Organization name is required"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Organization name is required", nil)
         return
     }
 
     // Set creator
     org.CreatedBy = userID
 
-    if err := h.service.CreateOrganization(c.Request.Context(), &org); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization: " + err.Error()})
+    if err := h.service.CreateOrganization(&org); err != nil {
+        var fieldErr *services.FieldError
+        if errors.As(err, &fieldErr) {
+            utils.ValidationErrorResponse(c, "Validation failed", []*services.FieldError{fieldErr})
+            return
+        }
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create organization", err)
         return
     }
 
@@ -72,16 +79,26 @@ func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
     idParam := c.Param("id")
     orgID, err := uuid.Parse(idParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID format", nil)
         return
     }
 
-    org, err := h.service.GetOrganizationByID(c.Request.Context(), orgID)
+    // Anonymous callers only see public organizations
+    currentUserID, _ := middleware.GetCurrentUserID(c)
+    userID, _ := uuid.Parse(currentUserID)
+
+    org, err := h.service.GetVisibleOrganization(userID, orgID)
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+        respondOrganizationAccessError(c, err, "Insufficient permissions to view this organization")
         return
     }
 
+    // Public profiles are the same for everyone and may be cached when the route is behind
+    // middleware.CacheControl; private ones only go to members
+    if org.Visibility == models.OrganizationVisibilityPrivate {
+        c.Header("Cache-Control", "no-store")
+    }
+
     c.JSON(http.StatusOK, org)
 }
 
@@ -90,54 +107,37 @@ func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
     idParam := c.Param("id")
     orgID, err := uuid.Parse(idParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID format"})
-        return
-    }
-
-    // Get authenticated user
-    currentUserID, exists := middleware.GetCurrentUserID(c)
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-        return
-    }
-
-    userID, err := uuid.Parse(currentUserID)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-        return
-    }
-
-    // Check if organization exists and user has access
-    existingOrg, err := h.service.GetOrganizationByID(c.Request.Context(), orgID)
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID format", nil)
         return
     }
 
-    // Check if user is the creator (basic access control)
-    if existingOrg.CreatedBy != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to update this organization"})
+    // Only the creator may update; the organization is reported missing to users who can't see it
+    if !h.authorizeOrganization(c, orgID, h.policy.CanEditOrg, "Insufficient permissions to update this organization") {
         return
     }
 
     var updateData models.Organization
-    if err := c.ShouldBindJSON(&updateData); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+    if !utils.BindJSON(c, &updateData) {
         return
     }
 
     // Set ID for update
     updateData.ID = orgID
 
-    if err := h.service.UpdateOrganization(c.Request.Context(), &updateData); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization: " + err.Error()})
+    if err := h.service.UpdateOrganization(&updateData); err != nil {
+        var fieldErr *services.FieldError
+        if errors.As(err, &fieldErr) {
+            utils.ValidationErrorResponse(c, "Validation failed", []*services.FieldError{fieldErr})
+            return
+        }
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update organization", err)
         return
     }
 
     // Get updated organization
-    updatedOrg, err := h.service.GetOrganizationByID(c.Request.Context(), orgID)
+    updatedOrg, err := h.service.GetOrganizationByID(orgID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated organization"})
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve updated organization", nil)
         return
     }
 
@@ -149,73 +149,196 @@ func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
     idParam := c.Param("id")
     orgID, err := uuid.Parse(idParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID format", nil)
         return
     }
 
-    // Get authenticated user
+    // Only the creator may delete; the organization is reported missing to users who can't see it
+    if !h.authorizeOrganization(c, orgID, h.policy.CanDeleteOrg, "Insufficient permissions to delete this organization") {
+        return
+    }
+
+    if err := h.service.DeleteOrganization(orgID); err != nil {
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete organization", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+// ListOrganizations handles retrieving organizations with pagination. Only public
+// organizations are listed; private ones stay visible to their members alone.
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+    page := utils.ParsePaginationParams(c)
+
+    organizations, err := h.service.ListPublicOrganizations("", page.Limit, page.Offset)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve organizations", nil)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "organizations": organizations.Organizations,
+        "limit":         page.Limit,
+        "offset":        page.Offset,
+        "count":         len(organizations.Organizations),
+        "total":         organizations.Total,
+    })
+}
+
+// ListPublicOrganizations godoc
+// @Summary List public organizations
+// @Description Get a paginated list of public organizations by name for the organization directory, without signing in. Private organizations are never listed.
+// @Tags Organizations
+// @Produce json
+// @Param q query string false "Only organizations whose name or slug contains this text (case-insensitive)"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of organizations per page (default 20, max 100)"
+// @Param offset query int false "Number of organizations to skip, used when page is not given"
+// @Success 200 {object} utils.SuccessResponse{data=models.PublicOrganizationPage}
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /organizations/public [get]
+func (h *OrganizationHandler) ListPublicOrganizations(c *gin.Context) {
+    page := utils.ParsePaginationParams(c)
+
+    organizations, err := h.service.ListPublicOrganizations(c.Query("q"), page.Limit, page.Offset)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Organizations retrieved successfully", organizations)
+}
+
+// GetOrganizationMembers godoc
+// @Summary List organization members
+// @Description Get the members of an organization with their role and join date. Only members can list them.
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of members per page (default 20, max 100)"
+// @Param offset query int false "Number of members to skip, used when page is not given"
+// @Success 200 {object} models.APIResponse "Organization members"
+// @Failure 400 {object} models.APIError "Invalid organization ID"
+// @Failure 401 {object} models.APIError "Unauthorized"
+// @Failure 403 {object} models.APIError "Not a member of the organization"
+// @Failure 404 {object} models.APIError "Organization not found"
+// @Router /organizations/{id}/members [get]
+func (h *OrganizationHandler) GetOrganizationMembers(c *gin.Context) {
     currentUserID, exists := middleware.GetCurrentUserID(c)
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
     userID, err := uuid.Parse(currentUserID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
         return
     }
 
-    // Check if organization exists and user has access
-    existingOrg, err := h.service.GetOrganizationByID(c.Request.Context(), orgID)
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+    orgID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
         return
     }
 
-    // Check if user is the creator (basic access control)
-    if existingOrg.CreatedBy != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to delete this organization"})
+    page := utils.ParsePaginationParams(c)
+
+    members, total, err := h.service.GetOrganizationMembers(userID, orgID, page.Limit, page.Offset)
+    if err != nil {
+        respondOrganizationAccessError(c, err, "Not a member of this organization")
         return
     }
 
-    if err := h.service.DeleteOrganization(c.Request.Context(), orgID); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization: " + err.Error()})
-        return
+    result := make([]gin.H, 0, len(members))
+    for _, member := range members {
+        result = append(result, gin.H{
+            "id":         member.User.ID,
+            "username":   member.User.Username,
+            "first_name": member.User.FirstName,
+            "last_name":  member.User.LastName,
+            "avatar":     member.User.Avatar,
+            "role":       member.Role,
+            "joined_at":  member.JoinedAt,
+        })
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+    c.JSON(http.StatusOK, gin.H{
+        "members": result,
+        "limit":   page.Limit,
+        "offset":  page.Offset,
+        "count":   len(result),
+        "total":   total,
+    })
 }
 
-// ListOrganizations handles retrieving organizations with pagination
-func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
-    // Parse pagination parameters
-    limit := 10
-    offset := 0
+// GetOrganizationProjects godoc
+// @Summary List organization projects
+// @Description Get a paginated list of an organization's projects, newest first. Only members can list them.
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of projects per page (default 20, max 100)"
+// @Param offset query int false "Number of projects to skip, used when page is not given"
+// @Success 200 {object} models.APIResponse "Organization projects"
+// @Failure 400 {object} models.APIError "Invalid organization ID"
+// @Failure 401 {object} models.APIError "Unauthorized"
+// @Failure 403 {object} models.APIError "Not a member of the organization"
+// @Failure 404 {object} models.APIError "Organization not found"
+// @Router /organizations/{id}/projects [get]
+func (h *OrganizationHandler) GetOrganizationProjects(c *gin.Context) {
+    currentUserID, exists := middleware.GetCurrentUserID(c)
+    if !exists {
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+        return
+    }
 
-    if limitStr := c.Query("limit"); limitStr != "" {
-        if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-            limit = l
-        }
+    userID, err := uuid.Parse(currentUserID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
+        return
     }
 
-    if offsetStr := c.Query("offset"); offsetStr != "" {
-        if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-            offset = o
-        }
+    orgID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
     }
 
-    organizations, err := h.service.ListOrganizations(c.Request.Context(), limit, offset)
+    page := utils.ParsePaginationParams(c)
+
+    projects, total, err := h.service.GetOrganizationProjects(userID, orgID, page.Limit, page.Offset)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations: " + err.Error()})
+        respondOrganizationAccessError(c, err, "Not a member of this organization")
         return
     }
 
+    result := make([]gin.H, 0, len(projects))
+    for _, project := range projects {
+        visibility := "private"
+        if project.IsPublic {
+            visibility = "public"
+        }
+        result = append(result, gin.H{
+            "id":          project.ID,
+            "name":        project.Name,
+            "description": project.Description,
+            "owner_id":    project.OwnerID,
+            "visibility":  visibility,
+            "created_at":  project.CreatedAt,
+            "updated_at":  project.UpdatedAt,
+        })
+    }
+
     c.JSON(http.StatusOK, gin.H{
-        "organizations": organizations,
-        "limit":         limit,
-        "offset":        offset,
-        "count":         len(organizations),
+        "projects": result,
+        "limit":    page.Limit,
+        "offset":   page.Offset,
+        "count":    len(result),
+        "total":    total,
     })
 }
 
@@ -223,19 +346,19 @@ func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
 func (h *OrganizationHandler) GetUserOrganizations(c *gin.Context) {
     currentUserID, exists := middleware.GetCurrentUserID(c)
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
     userID, err := uuid.Parse(currentUserID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
         return
     }
 
-    organizations, err := h.service.GetOrganizationsByUserID(c.Request.Context(), userID)
+    organizations, err := h.service.GetOrganizationsByUserID(userID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user organizations: " + err.Error()})
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve user organizations", err)
         return
     }
 
@@ -250,7 +373,11 @@ func (h *OrganizationHandler) AddUserToOrganization(c *gin.Context) {
     orgIDParam := c.Param("id")
     orgID, err := uuid.Parse(orgIDParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID format", nil)
+        return
+    }
+
+    if !h.authorizeOrganization(c, orgID, h.policy.CanManageMembers, "Insufficient permissions to add members to this organization") {
         return
     }
 
@@ -258,19 +385,25 @@ func (h *OrganizationHandler) AddUserToOrganization(c *gin.Context) {
         UserID string `json:"user_id" binding:"required"`
     }
 
-    if err := c.ShouldBindJSON(&requestData); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+    if !utils.BindJSON(c, &requestData) {
         return
     }
 
     userID, err := uuid.Parse(requestData.UserID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format", nil)
         return
     }
 
-    if err := h.service.AddUserToOrganization(c.Request.Context(), orgID, userID); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add user to organization: " + err.Error()})
+    if err := h.service.AddUserToOrganization(orgID, userID); err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
+        case errors.Is(err, services.ErrConflict):
+            utils.ErrorResponse(c, http.StatusConflict, "User is already a member of this organization", nil)
+        default:
+            utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to add user to organization", nil)
+        }
         return
     }
 
@@ -282,19 +415,23 @@ func (h *OrganizationHandler) RemoveUserFromOrganization(c *gin.Context) {
     orgIDParam := c.Param("id")
     orgID, err := uuid.Parse(orgIDParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID format", nil)
         return
     }
 
     userIDParam := c.Param("user_id")
     userID, err := uuid.Parse(userIDParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format", nil)
+        return
+    }
+
+    if !h.authorizeOrganization(c, orgID, h.policy.CanManageMembers, "Insufficient permissions to remove members from this organization") {
         return
     }
 
-    if err := h.service.RemoveUserFromOrganization(c.Request.Context(), orgID, userID); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user from organization: " + err.Error()})
+    if err := h.service.RemoveMember(orgID, userID); err != nil {
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to remove user from organization", nil)
         return
     }
 
@@ -305,15 +442,59 @@ func (h *OrganizationHandler) RemoveUserFromOrganization(c *gin.Context) {
 func (h *OrganizationHandler) GetOrganizationByName(c *gin.Context) {
     name := c.Query("name")
     if name == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Organization name parameter is required"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Organization name parameter is required", nil)
         return
     }
 
-    org, err := h.service.GetOrganizationByName(c.Request.Context(), name)
+    // Anonymous callers only find public organizations
+    currentUserID, _ := middleware.GetCurrentUserID(c)
+    userID, _ := uuid.Parse(currentUserID)
+
+    org, err := h.service.GetOrganizationByName(userID, name)
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+        respondOrganizationAccessError(c, err, "Insufficient permissions to view this organization")
         return
     }
 
     c.JSON(http.StatusOK, org)
-}
\ No newline at end of file
+}
+
+// authorizeOrganization checks the current user against one of the policy rules for the
+// organization and writes the error response when they may not go ahead
+func (h *OrganizationHandler) authorizeOrganization(c *gin.Context, orgID uuid.UUID, rule func(uuid.UUID, *models.Organization) error, forbiddenMessage string) bool {
+    currentUserID, exists := middleware.GetCurrentUserID(c)
+    if !exists {
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+        return false
+    }
+
+    userID, err := uuid.Parse(currentUserID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
+        return false
+    }
+
+    org, err := h.service.GetVisibleOrganization(userID, orgID)
+    if err == nil {
+        err = rule(userID, org)
+    }
+    if err != nil {
+        respondOrganizationAccessError(c, err, forbiddenMessage)
+        return false
+    }
+    return true
+}
+
+// respondOrganizationAccessError writes the response for a failed organization access check.
+// Private organizations the caller can't see are reported as not found.
+func respondOrganizationAccessError(c *gin.Context, err error, forbiddenMessage string) {
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        utils.ErrorResponse(c, http.StatusNotFound, "Organization not found", nil)
+    case errors.Is(err, services.ErrForbidden):
+        utils.ErrorResponse(c, http.StatusForbidden, forbiddenMessage, nil)
+    default:
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to retrieve organization")
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve organization", nil)
+    }
+}
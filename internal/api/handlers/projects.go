@@ -1,9 +1,12 @@
 package handlers
 
 import (
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/models"
     "collabhub-music-backend/internal/services"
     "collabhub-music-backend/internal/utils"
     "net/http"
+    "time"
 
     "github.com/gin-gonic/gin"
     "github.com/google/uuid"
@@ -21,14 +24,19 @@ func NewProjectHandler(projectService *services.ProjectService) *ProjectHandler
     }
 }
 
-// GetProjects retrieves all projects for the authenticated user
-// @Summary Get user projects
-// @Description Get all projects where user is owner or collaborator
+// GetProjects returns the projects of the authenticated user
+// @Summary List user projects
+// @Description Get a paginated list of the projects the authenticated user owns or collaborates on, with the user's role in each, most recently active first
 // @Tags projects
 // @Accept json
 // @Produce json
 // @Security Bearer
-// @Success 200 {object} utils.SuccessResponse{data=[]models.Project}
+// @Param role query string false "Only list projects where the user has this role" Enums(owner, admin, collaborator, viewer)
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of projects per page (default 20, max 100)"
+// @Param offset query int false "Number of projects to skip, used when page is not given"
+// @Success 200 {object} utils.SuccessResponse{data=models.UserProjectPage}
+// @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /projects [get]
@@ -40,7 +48,34 @@ func (h *ProjectHandler) GetProjects(c *gin.Context) {
         return
     }
 
-    projects, err := h.projectService.GetUserProjects(parsedUserID)
+    page := utils.ParsePaginationParams(c)
+
+    projects, err := h.projectService.GetUserProjects(parsedUserID, c.Query("role"), page.Limit, page.Offset)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Projects retrieved successfully", projects)
+}
+
+// ListPublicProjects returns the public projects for discovery
+// @Summary List public projects
+// @Description Get a paginated list of public projects for browsing, without signing in. Popular projects are those with the most collaborators, then the most files.
+// @Tags projects
+// @Produce json
+// @Param sort query string false "Order of the projects (default recent)" Enums(recent, popular)
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of projects per page (default 20, max 100)"
+// @Param offset query int false "Number of projects to skip, used when page is not given"
+// @Success 200 {object} utils.SuccessResponse{data=models.PublicProjectPage}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /projects/public [get]
+func (h *ProjectHandler) ListPublicProjects(c *gin.Context) {
+    page := utils.ParsePaginationParams(c)
+
+    projects, err := h.projectService.ListPublicProjects(c.Query("sort"), page.Limit, page.Offset)
     if err != nil {
         utils.HandleServiceError(c, err)
         return
@@ -56,10 +91,12 @@ func (h *ProjectHandler) GetProjects(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security Bearer
-// @Param request body services.CreateProjectRequest true "Project data"
+// @Param request body models.CreateProjectRequest true "Project data"
 // @Success 201 {object} utils.SuccessResponse{data=models.Project}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse "Not an owner or admin of the organization"
+// @Failure 404 {object} utils.ErrorResponse "Organization not found"
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /projects [post]
 func (h *ProjectHandler) CreateProject(c *gin.Context) {
@@ -70,14 +107,20 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
         return
     }
 
-    var req services.CreateProjectRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
+    var req models.CreateProjectRequest
+    if !utils.BindJSON(c, &req) {
         return
     }
 
-    project, err := h.projectService.CreateProject(parsedUserID, &req)
-    if err != nil {
+    project := &models.Project{
+        Name:           req.Name,
+        Description:    req.Description,
+        IsPublic:       req.IsPublic,
+        OrganizationID: req.OrganizationID,
+        OwnerID:        parsedUserID,
+        CreatedBy:      parsedUserID,
+    }
+    if err := h.projectService.CreateProject(project); err != nil {
         utils.HandleServiceError(c, err)
         return
     }
@@ -87,7 +130,7 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 
 // GetProject retrieves a specific project
 // @Summary Get project
-// @Description Get project by ID
+// @Description Get a project by ID. Private projects the user is not a member of are reported as not found.
 // @Tags projects
 // @Accept json
 // @Produce json
@@ -107,9 +150,8 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
         return
     }
 
-    projectID, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err)
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
         return
     }
 
@@ -124,13 +166,13 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 
 // UpdateProject updates an existing project
 // @Summary Update project
-// @Description Update project information
+// @Description Update the name, description or visibility of a project (only owner and admins)
 // @Tags projects
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path string true "Project ID"
-// @Param request body services.UpdateProjectRequest true "Project update data"
+// @Param request body models.UpdateProjectRequest true "Project update data"
 // @Success 200 {object} utils.SuccessResponse{data=models.Project}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -145,15 +187,13 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
         return
     }
 
-    projectID, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err)
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
         return
     }
 
-    var req services.UpdateProjectRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
+    var req models.UpdateProjectRequest
+    if !utils.BindJSON(c, &req) {
         return
     }
 
@@ -166,6 +206,48 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
     utils.SuccessResponse(c, http.StatusOK, "Project updated successfully", project)
 }
 
+// UpdateProjectSettings updates the settings of a project
+// @Summary Update project settings
+// @Description Replace the sample rate, bit depth, tempo, time signature and key of a project (only owner and admins)
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param request body models.ProjectSettings true "Project settings"
+// @Success 200 {object} utils.SuccessResponse{data=models.Project}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/settings [put]
+func (h *ProjectHandler) UpdateProjectSettings(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    var settings models.ProjectSettings
+    if !utils.BindJSON(c, &settings) {
+        return
+    }
+
+    project, err := h.projectService.UpdateProjectSettings(parsedUserID, projectID, settings)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Project settings updated successfully", project)
+}
+
 // DeleteProject deletes a project
 // @Summary Delete project
 // @Description Delete a project (only owner)
@@ -188,9 +270,8 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
         return
     }
 
-    projectID, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err)
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
         return
     }
 
@@ -205,7 +286,7 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 
 // AddCollaborator adds a collaborator to a project
 // @Summary Add collaborator
-// @Description Add a user as collaborator to a project
+// @Description Add a user as collaborator to a project. Without a role they get the configured default role (viewer unless changed).
 // @Tags projects
 // @Accept json
 // @Produce json
@@ -226,15 +307,13 @@ func (h *ProjectHandler) AddCollaborator(c *gin.Context) {
         return
     }
 
-    projectID, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err)
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
         return
     }
 
     var req AddCollaboratorRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
+    if !utils.BindJSON(c, &req) {
         return
     }
 
@@ -253,9 +332,127 @@ func (h *ProjectHandler) AddCollaborator(c *gin.Context) {
     utils.SuccessResponse(c, http.StatusOK, "Collaborator added successfully", nil)
 }
 
+// GetCollaborators lists the collaborators of a project
+// @Summary List collaborators
+// @Description List the collaborators of a project, including pending invitations (joined_at is null until accepted)
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.ProjectCollaborator}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/collaborators [get]
+func (h *ProjectHandler) GetCollaborators(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    collaborators, err := h.projectService.ListCollaborators(parsedUserID, projectID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Collaborators retrieved successfully", collaborators)
+}
+
+// InviteCollaborator invites a user to a project
+// @Summary Invite collaborator
+// @Description Invite a user to a project; they become a collaborator once they accept
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param request body AddCollaboratorRequest true "Invitation data"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/invitations [post]
+func (h *ProjectHandler) InviteCollaborator(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req AddCollaboratorRequest
+    if !utils.BindJSON(c, &req) {
+        return
+    }
+
+    inviteeID, err := uuid.Parse(req.UserID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid collaborator ID", err)
+        return
+    }
+
+    err = h.projectService.InviteCollaborator(parsedUserID, projectID, inviteeID, req.Role)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusCreated, "Invitation sent successfully", nil)
+}
+
+// AcceptInvitation accepts the current user's invitation to a project
+// @Summary Accept invitation
+// @Description Accept an invitation to a project, recording when the user joined
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.ProjectCollaborator}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/invitations/accept [post]
+func (h *ProjectHandler) AcceptInvitation(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    collaborator, err := h.projectService.AcceptInvitation(parsedUserID, projectID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Invitation accepted successfully", collaborator)
+}
+
 // RemoveCollaborator removes a collaborator from a project
 // @Summary Remove collaborator
-// @Description Remove a collaborator from a project
+// @Description Remove a collaborator from a project (only owner and admins)
 // @Tags projects
 // @Accept json
 // @Produce json
@@ -276,15 +473,13 @@ func (h *ProjectHandler) RemoveCollaborator(c *gin.Context) {
         return
     }
 
-    projectID, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid project ID", err)
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
         return
     }
 
-    collaboratorID, err := uuid.Parse(c.Param("userId"))
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid collaborator ID", err)
+    collaboratorID, ok := middleware.ParamUUID(c, "userId")
+    if !ok {
         return
     }
 
@@ -297,8 +492,428 @@ func (h *ProjectHandler) RemoveCollaborator(c *gin.Context) {
     utils.SuccessResponse(c, http.StatusOK, "Collaborator removed successfully", nil)
 }
 
+// GetProjectActivity retrieves the activity feed of a project
+// @Summary Get project activity
+// @Description Get a paginated feed of project events (files added, versions created, collaborators joined, branches created), newest first. With since, events after it come oldest first, so a client can page through them and poll again from the created_at of the last one.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param since query string false "Only return events after this RFC3339 timestamp"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of events per page (default 20, max 100)"
+// @Param offset query int false "Number of events to skip, used when page is not given"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.ActivityEvent}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/activity [get]
+func (h *ProjectHandler) GetProjectActivity(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    var since *time.Time
+    if sinceStr := c.Query("since"); sinceStr != "" {
+        parsedSince, err := time.Parse(time.RFC3339, sinceStr)
+        if err != nil {
+            utils.ErrorResponse(c, http.StatusBadRequest, "Invalid since timestamp, expected RFC3339", err)
+            return
+        }
+        since = &parsedSince
+    }
+
+    page := utils.ParsePaginationParams(c)
+
+    events, err := h.projectService.GetProjectActivity(parsedUserID, projectID, since, page.Limit, page.Offset)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Project activity retrieved successfully", events)
+}
+
 // Request structs
 type AddCollaboratorRequest struct {
     UserID string `json:"user_id" binding:"required"`
-    Role   string `json:"role" binding:"required,oneof=admin collaborator viewer"`
-}
\ No newline at end of file
+    Role   string `json:"role" binding:"omitempty,oneof=admin collaborator viewer"` // DEFAULT_COLLABORATOR_ROLE when empty
+}
+
+// GetProjectStorageUsage retrieves the storage used by a project
+// @Summary Get project storage usage
+// @Description Get the total bytes and file count of a project, split between audio and other files, with its largest files
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.StorageUsage}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/usage [get]
+func (h *ProjectHandler) GetProjectStorageUsage(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    usage, err := h.projectService.GetProjectStorageUsage(parsedUserID, projectID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Project storage usage retrieved successfully", usage)
+}
+
+// BatchDeleteProjects deletes several projects at once
+// @Summary Delete projects in batch
+// @Description Delete up to 100 projects the caller owns in one transaction. Each id is reported as deleted, forbidden or not_found; projects the caller doesn't own are left untouched.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body models.BatchDeleteProjectsRequest true "Projects to delete"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.BatchDeleteResult}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Router /projects/batch-delete [post]
+func (h *ProjectHandler) BatchDeleteProjects(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    var req models.BatchDeleteProjectsRequest
+    if !utils.BindJSON(c, &req) {
+        return
+    }
+
+    results, err := h.projectService.BatchDeleteProjects(parsedUserID, req.ProjectIDs)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Batch delete completed", results)
+}
+
+// FavoriteProject adds a project to the user's favorites
+// @Summary Favorite project
+// @Description Add a project the user is a member of to their favorites. Favoriting it again has no effect.
+// @Tags projects
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/favorite [post]
+func (h *ProjectHandler) FavoriteProject(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    if err := h.projectService.FavoriteProject(parsedUserID, projectID); err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Project added to favorites", nil)
+}
+
+// UnfavoriteProject removes a project from the user's favorites
+// @Summary Unfavorite project
+// @Description Remove a project from the user's favorites. Projects that aren't favorites are ignored.
+// @Tags projects
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /projects/{id}/favorite [delete]
+func (h *ProjectHandler) UnfavoriteProject(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    if err := h.projectService.UnfavoriteProject(parsedUserID, projectID); err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Project removed from favorites", nil)
+}
+
+// ListFavoriteProjects lists the user's favorite projects
+// @Summary List favorite projects
+// @Description List the projects the user favorited and can still access, most recently favorited first
+// @Tags users
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} utils.SuccessResponse{data=[]models.FavoriteProject}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /users/me/favorites [get]
+func (h *ProjectHandler) ListFavoriteProjects(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projects, err := h.projectService.ListFavoriteProjects(parsedUserID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Favorite projects retrieved successfully", projects)
+}
+
+// GetUserSummary counts the user's resources for their dashboard
+// @Summary Get user summary
+// @Description Count the projects the user owns, created or collaborates on, their organizations, the bytes stored in the projects they own and the activity in their projects over the last 7 days. Counts are cached for up to 30 seconds.
+// @Tags users
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} utils.SuccessResponse{data=models.UserSummary}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /users/me/summary [get]
+func (h *ProjectHandler) GetUserSummary(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    summary, err := h.projectService.GetUserSummary(parsedUserID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "User summary retrieved successfully", summary)
+}
+
+// ListBranches lists the branches of a project
+// @Summary List project branches
+// @Description Get the branches of a project, default branch first, each with its file count and last update
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.BranchSummary}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/branches [get]
+func (h *ProjectHandler) ListBranches(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    branches, err := h.projectService.ListBranches(parsedUserID, projectID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Branches retrieved successfully", branches)
+}
+
+// GetBranchFiles retrieves the files on a project branch
+// @Summary List branch files
+// @Description Get the files on a branch of a project, with their audio metadata
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param branchId path string true "Branch ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.File}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/branches/{branchId}/files [get]
+func (h *ProjectHandler) GetBranchFiles(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    branchID, ok := middleware.ParamUUID(c, "branchId")
+    if !ok {
+        return
+    }
+
+    files, err := h.projectService.GetBranchFiles(parsedUserID, projectID, branchID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Branch files retrieved successfully", files)
+}
+
+// MergeBranch merges a branch into another branch of the same project
+// @Summary Merge branches
+// @Description Merge the files of a branch into a target branch. Files only on the source are added, the newest version wins otherwise, and files changed on both branches are reported as conflicts and left untouched Viewers can't merge branches.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param branchId path string true "Source branch ID"
+// @Param request body models.MergeBranchRequest true "Target branch"
+// @Success 200 {object} utils.SuccessResponse{data=models.MergeResult}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /projects/{id}/branches/{branchId}/merge [post]
+func (h *ProjectHandler) MergeBranch(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    branchID, ok := middleware.ParamUUID(c, "branchId")
+    if !ok {
+        return
+    }
+
+    var req models.MergeBranchRequest
+    if !utils.BindJSON(c, &req) {
+        return
+    }
+
+    result, err := h.projectService.MergeBranch(parsedUserID, projectID, branchID, req.TargetBranchID)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Branch merged successfully", result)
+}
+
+// RenameBranch renames a branch of a project
+// @Summary Rename branch
+// @Description Rename a branch of a project. Renaming the default branch also changes the project's current_branch. Only the owner and admins may rename branches.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Project ID"
+// @Param branchId path string true "Branch ID"
+// @Param request body models.RenameBranchRequest true "New branch name"
+// @Success 200 {object} utils.SuccessResponse{data=models.Branch}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse "Another branch of the project has that name"
+// @Router /projects/{id}/branches/{branchId}/rename [put]
+func (h *ProjectHandler) RenameBranch(c *gin.Context) {
+    userID := c.GetString("user_id")
+    parsedUserID, err := uuid.Parse(userID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+        return
+    }
+
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    branchID, ok := middleware.ParamUUID(c, "branchId")
+    if !ok {
+        return
+    }
+
+    var req models.RenameBranchRequest
+    if !utils.BindJSON(c, &req) {
+        return
+    }
+
+    branch, err := h.projectService.RenameBranch(parsedUserID, projectID, branchID, req.Name)
+    if err != nil {
+        utils.HandleServiceError(c, err)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "Branch renamed successfully", branch)
+}
@@ -1,21 +1,39 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
-    "fmt"
+    "strconv"
+    "time"
     "github.com/gin-gonic/gin"
     "github.com/google/uuid"
     "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/internal/utils"
     "collabhub-music-backend/internal/models"
     "collabhub-music-backend/internal/middleware"
 )
 
+// Username availability and profile lookups are limited per client IP so the
+// endpoints can't be used to enumerate registered usernames
+const (
+    usernameAvailabilityLimit  = 10
+    usernameAvailabilityWindow = time.Minute
+    profileLookupLimit         = 30
+    profileLookupWindow        = time.Minute
+)
+
 type UserHandler struct {
-    userService *services.UserService
+    userService     *services.UserService
+    usernameLimiter *middleware.RateLimiter
+    profileLimiter  *middleware.RateLimiter
 }
 
 func NewUserHandler(userService *services.UserService) *UserHandler {
-    return &UserHandler{userService: userService}
+    return &UserHandler{
+        userService:     userService,
+        usernameLimiter: middleware.NewRateLimiter(usernameAvailabilityLimit, usernameAvailabilityWindow),
+        profileLimiter:  middleware.NewRateLimiter(profileLookupLimit, profileLookupWindow),
+    }
 }
 
 
@@ -33,24 +51,23 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 // @Router /users/register [post]
 func (h *UserHandler) RegisterUser(c *gin.Context) {
     var user models.User
-    if err := c.ShouldBindJSON(&user); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+    if !utils.BindJSON(c, &user) {
         return
     }
 
     // Basic validation
     if user.Username == "" || user.Email == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Username and email are required"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Username and email are required", nil)
         return
     }
 
     if user.FirstName == "" || user.LastName == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "First name and last name are required"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "First name and last name are required", nil)
         return
     }
 
-    if err := h.userService.CreateUser(c.Request.Context(), &user); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user: " + err.Error()})
+    if err := h.userService.CreateUser(&user); err != nil {
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create user", err)
         return
     }
 
@@ -67,6 +84,68 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
     c.JSON(http.StatusCreated, response)
 }
 
+// CheckUsernameAvailable godoc
+// @Summary Check username availability
+// @Description Report whether a username is still free, normalized the same way as on registration
+// @Tags Authentication
+// @Produce json
+// @Param username query string true "Username to check"
+// @Success 200 {object} map[string]bool "Availability of the username"
+// @Failure 400 {object} models.APIError "Bad request"
+// @Failure 429 {object} models.APIError "Too many requests"
+// @Failure 500 {object} models.APIError "Internal server error"
+// @Router /users/username-available [get]
+func (h *UserHandler) CheckUsernameAvailable(c *gin.Context) {
+    if allowed, retryAfter := h.usernameLimiter.Allow(c.ClientIP()); !allowed {
+        middleware.SetRetryAfter(c, retryAfter)
+        utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many requests", nil)
+        return
+    }
+
+    available, err := h.userService.IsUsernameAvailable(c.Query("username"))
+    if err != nil {
+        if errors.Is(err, services.ErrInvalid) {
+            utils.ErrorResponse(c, http.StatusBadRequest, "Invalid username", err)
+            return
+        }
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check username", nil)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"available": available})
+}
+
+// GetUserByUsername godoc
+// @Summary Get a user's public profile by username
+// @Description Get the public profile of a user by username, normalized the same way as on registration, e.g. to link to them or mention them. Deactivated users are not found.
+// @Tags Users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} models.APIResponse{data=models.PublicUserProfile} "Public profile"
+// @Failure 404 {object} models.APIError "User not found"
+// @Failure 429 {object} models.APIError "Too many requests"
+// @Failure 500 {object} models.APIError "Internal server error"
+// @Router /users/by-username/{username} [get]
+func (h *UserHandler) GetUserByUsername(c *gin.Context) {
+    if allowed, retryAfter := h.profileLimiter.Allow(c.ClientIP()); !allowed {
+        middleware.SetRetryAfter(c, retryAfter)
+        utils.ErrorResponse(c, http.StatusTooManyRequests, "Too many requests", nil)
+        return
+    }
+
+    profile, err := h.userService.GetPublicProfile(c.Param("username"))
+    if err != nil {
+        if errors.Is(err, services.ErrNotFound) {
+            utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
+            return
+        }
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get user", nil)
+        return
+    }
+
+    utils.SuccessResponse(c, http.StatusOK, "User retrieved successfully", profile)
+}
+
 // UpdateUserProfile godoc
 // @Summary Update current user profile
 // @Description Update the profile of the currently authenticated user
@@ -74,51 +153,42 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param user body models.UserUpdateRequest true "User update data"
+// @Param user body models.UserProfileUpdate true "User update data"
 // @Success 200 {object} models.APIResponse{data=models.User} "User updated successfully"
 // @Failure 400 {object} models.APIError "Bad request"
 // @Failure 401 {object} models.APIError "Unauthorized"
 // @Failure 404 {object} models.APIError "User not found"
 // @Failure 500 {object} models.APIError "Internal server error"
+// @Failure 502 {object} models.APIError "Keycloak rejected the update"
 // @Router /users/me [put]
 func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
-    userIDParam := c.Param("id")
-    userID, err := uuid.Parse(userIDParam)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
-        return
-    }
-
-    // Check if current user is updating their own profile
     currentUserID, exists := middleware.GetCurrentUserID(c)
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
-    if currentUserID != userID.String() {
-        c.JSON(http.StatusForbidden, gin.H{"error": "Cannot update another user's profile"})
+    userID, err := uuid.Parse(currentUserID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
         return
     }
 
-    var updateData models.User
-    if err := c.ShouldBindJSON(&updateData); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+    var update models.UserProfileUpdate
+    if !utils.BindJSON(c, &update) {
         return
     }
 
-    // Set the ID for update
-    updateData.ID = userID
-
-    if err := h.userService.UpdateUser(c.Request.Context(), &updateData); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user: " + err.Error()})
-        return
-    }
-
-    // Get updated user data
-    updatedUser, err := h.userService.GetUserByID(c.Request.Context(), userID)
+    updatedUser, err := h.userService.UpdateProfile(c.Request.Context(), userID, &update)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated user"})
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
+        case errors.Is(err, services.ErrIdentityProvider):
+            utils.ErrorResponse(c, http.StatusBadGateway, "Failed to update user", err)
+        default:
+            utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update user", nil)
+        }
         return
     }
 
@@ -129,6 +199,7 @@ func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
         "email":      updatedUser.Email,
         "first_name": updatedUser.FirstName,
         "last_name":  updatedUser.LastName,
+        "email_verified": updatedUser.EmailVerified,
         "updated_at": updatedUser.UpdatedAt,
     }
 
@@ -140,13 +211,13 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
     userIDParam := c.Param("id")
     userID, err := uuid.Parse(userIDParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format", nil)
         return
     }
 
-    user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+    user, err := h.userService.GetUserByID(userID)
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
         return
     }
 
@@ -166,62 +237,49 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
     currentUserID, exists := middleware.GetCurrentUserID(c)
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
     userID, err := uuid.Parse(currentUserID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", nil)
         return
     }
 
-    user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+    user, err := h.userService.GetUserByID(userID)
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
         return
     }
 
     // Return full profile data for current user
     response := gin.H{
-        "id":         user.ID,
-        "username":   user.Username,
-        "email":      user.Email,
-        "first_name": user.FirstName,
-        "last_name":  user.LastName,
-        "created_at": user.CreatedAt,
-        "updated_at": user.UpdatedAt,
+        "id":            user.ID,
+        "username":      user.Username,
+        "email":         user.Email,
+        "first_name":    user.FirstName,
+        "last_name":     user.LastName,
+        "last_login_at": user.LastLoginAt,
+        "created_at":    user.CreatedAt,
+        "updated_at":    user.UpdatedAt,
     }
 
     c.JSON(http.StatusOK, response)
 }
 
-// ListUsers handles retrieving a paginated list of users
+// ListUsers handles retrieving a paginated list of users, by username
 func (h *UserHandler) ListUsers(c *gin.Context) {
-    // Parse query parameters
-    limit := 10
-    offset := 0
+    page := utils.ParsePaginationParams(c)
 
-    if limitStr := c.Query("limit"); limitStr != "" {
-        if l, err := parseInt(limitStr); err == nil && l > 0 {
-            limit = l
-        }
-    }
-
-    if offsetStr := c.Query("offset"); offsetStr != "" {
-        if o, err := parseInt(offsetStr); err == nil && o >= 0 {
-            offset = o
-        }
-    }
-
-    users, err := h.userService.ListUsers(c.Request.Context(), limit, offset)
+    users, total, err := h.userService.SearchUsers("", false, page.Limit, page.Offset)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve users", nil)
         return
     }
 
     // Return public data only
-    var response []gin.H
+    response := make([]gin.H, 0, len(users))
     for _, user := range users {
         response = append(response, gin.H{
             "id":         user.ID,
@@ -234,46 +292,199 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
     c.JSON(http.StatusOK, gin.H{
         "users":  response,
-        "limit":  limit,
-        "offset": offset,
+        "limit":  page.Limit,
+        "offset": page.Offset,
         "count":  len(response),
+        "total":  total,
     })
 }
 
-// DeleteUser handles user deletion
+// DeleteUser godoc
+// @Summary Delete a user account
+// @Description Delete a user account locally and in Keycloak. Allowed for the account owner and admins.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.APIResponse "User deleted successfully"
+// @Failure 400 {object} models.APIError "Bad request"
+// @Failure 401 {object} models.APIError "Unauthorized"
+// @Failure 403 {object} models.APIError "Forbidden"
+// @Failure 404 {object} models.APIError "User not found"
+// @Failure 502 {object} models.APIError "Keycloak rejected the deletion"
+// @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
     userIDParam := c.Param("id")
     userID, err := uuid.Parse(userIDParam)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+        utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format", nil)
         return
     }
 
-    // Check if current user is deleting their own account
     currentUserID, exists := middleware.GetCurrentUserID(c)
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
-    if currentUserID != userID.String() {
-        c.JSON(http.StatusForbidden, gin.H{"error": "Cannot delete another user's account"})
+    actorID, err := uuid.Parse(currentUserID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
         return
     }
 
-    if err := h.userService.DeleteUser(c.Request.Context(), userID); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user: " + err.Error()})
+    if err := h.userService.DeleteAccount(c.Request.Context(), actorID, userID, hasRole(c, "admin")); err != nil {
+        switch {
+        case errors.Is(err, services.ErrForbidden):
+            utils.ErrorResponse(c, http.StatusForbidden, "Cannot delete another user's account", nil)
+        case errors.Is(err, services.ErrNotFound):
+            utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
+        case errors.Is(err, services.ErrIdentityProvider):
+            utils.ErrorResponse(c, http.StatusBadGateway, "Failed to delete user", err)
+        default:
+            utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete user", nil)
+        }
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
-// Helper function to parse integers
-func parseInt(s string) (int, error) {
-    var result int
-    if _, err := fmt.Sscanf(s, "%d", &result); err != nil {
-        return 0, err
+// DeactivateUser godoc
+// @Summary Deactivate a user account
+// @Description Disable an account without deleting it, locally and in Keycloak. Only the account owner or an admin may do this.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.APIResponse "User deactivated"
+// @Failure 400 {object} models.APIError "Bad request"
+// @Failure 401 {object} models.APIError "Unauthorized"
+// @Failure 403 {object} models.APIError "Forbidden"
+// @Failure 404 {object} models.APIError "User not found"
+// @Failure 502 {object} models.APIError "Keycloak rejected the change"
+// @Router /users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+    h.setUserActive(c, false)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a user account
+// @Description Enable a deactivated account again, locally and in Keycloak. Only the account owner or an admin may do this.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.APIResponse "User reactivated"
+// @Failure 400 {object} models.APIError "Bad request"
+// @Failure 401 {object} models.APIError "Unauthorized"
+// @Failure 403 {object} models.APIError "Forbidden"
+// @Failure 404 {object} models.APIError "User not found"
+// @Failure 502 {object} models.APIError "Keycloak rejected the change"
+// @Router /users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+    h.setUserActive(c, true)
+}
+
+// setUserActive deactivates or reactivates the user of the request path
+func (h *UserHandler) setUserActive(c *gin.Context, active bool) {
+    userID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
     }
-    return result, nil
-}
\ No newline at end of file
+
+    currentUserID, exists := middleware.GetCurrentUserID(c)
+    if !exists {
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+        return
+    }
+
+    actorID, err := uuid.Parse(currentUserID)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+        return
+    }
+
+    user, err := h.userService.SetAccountActive(c.Request.Context(), actorID, userID, hasRole(c, "admin"), active)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrForbidden):
+            utils.ErrorResponse(c, http.StatusForbidden, "Cannot change another user's account", nil)
+        case errors.Is(err, services.ErrNotFound):
+            utils.ErrorResponse(c, http.StatusNotFound, "User not found", nil)
+        case errors.Is(err, services.ErrIdentityProvider):
+            utils.ErrorResponse(c, http.StatusBadGateway, "Failed to update account status", err)
+        default:
+            utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update account status", nil)
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "id":        user.ID,
+        "username":  user.Username,
+        "is_active": user.IsActive,
+    })
+}
+
+// hasRole reports whether the authenticated user's token carries the realm role
+func hasRole(c *gin.Context, role string) bool {
+    roles, _ := c.Get("roles")
+    userRoles, _ := roles.([]string)
+    for _, userRole := range userRoles {
+        if userRole == role {
+            return true
+        }
+    }
+    return false
+}
+
+// SearchUsers godoc
+// @Summary Search users (admin)
+// @Description Search users by partial username or email. Requires the admin role.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Partial username or email"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of users per page (default 20, max 100)"
+// @Param offset query int false "Number of users to skip, used when page is not given"
+// @Param include_deleted query bool false "Include soft-deleted users"
+// @Success 200 {object} models.APIResponse "Matching users"
+// @Failure 401 {object} models.APIError "Unauthorized"
+// @Failure 403 {object} models.APIError "Forbidden"
+// @Failure 500 {object} models.APIError "Internal server error"
+// @Router /admin/users [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+    page := utils.ParsePaginationParams(c)
+
+    includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+
+    users, total, err := h.userService.SearchUsers(c.Query("q"), includeDeleted, page.Limit, page.Offset)
+    if err != nil {
+        utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search users", nil)
+        return
+    }
+
+    // Return public data only
+    response := make([]gin.H, 0, len(users))
+    for _, user := range users {
+        response = append(response, gin.H{
+            "id":         user.ID,
+            "username":   user.Username,
+            "first_name": user.FirstName,
+            "last_name":  user.LastName,
+            "avatar":     user.Avatar,
+            "created_at": user.CreatedAt,
+            "deleted":    user.DeletedAt.Valid,
+        })
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "users":  response,
+        "limit":  page.Limit,
+        "offset": page.Offset,
+        "count":  len(response),
+        "total":  total,
+    })
+}
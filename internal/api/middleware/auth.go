@@ -6,6 +6,7 @@ import (
 
     "github.com/gin-gonic/gin"
     "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/pkg/utils"
 )
 
 type AuthMiddleware struct {
@@ -27,28 +28,28 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+            utils.RespondError(c, http.StatusUnauthorized, "Authorization header required")
             c.Abort()
             return
         }
 
         tokenString := strings.TrimPrefix(authHeader, "Bearer ")
         if tokenString == authHeader {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+            utils.RespondError(c, http.StatusUnauthorized, "Bearer token required")
             c.Abort()
             return
         }
 
         // Valider le token avec Keycloak
-        isValid, err := a.keycloakService.ValidateToken(c.Request.Context(), tokenString)
+        introspection, err := a.keycloakService.IntrospectToken(c.Request.Context(), tokenString)
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to validate token"})
+            utils.RespondError(c, http.StatusUnauthorized, "Failed to validate token")
             c.Abort()
             return
         }
 
-        if !isValid {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+        if !introspection.Active {
+            utils.RespondError(c, http.StatusUnauthorized, "Invalid or expired token")
             c.Abort()
             return
         }
@@ -56,7 +57,14 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
         // Synchroniser l'utilisateur depuis Keycloak
         user, err := a.userService.SyncUserFromKeycloak(c.Request.Context(), tokenString)
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to sync user data"})
+            utils.RespondError(c, http.StatusUnauthorized, "Failed to sync user data")
+            c.Abort()
+            return
+        }
+
+        // A valid token doesn't outlive the account it was issued for
+        if !user.CanSignIn() {
+            utils.RespondErrorWithCode(c, http.StatusForbidden, utils.ErrCodeAccountInactive, "This account has been deactivated or deleted")
             c.Abort()
             return
         }
@@ -67,6 +75,8 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
         c.Set("username", user.Username)
         c.Set("email", user.Email)
         c.Set("user", user)
+        // Les rôles du realm, lus par RequireRole et RequireAnyRole
+        c.Set("roles", introspection.RealmRoles)
 
         c.Next()
     }
@@ -95,8 +105,8 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
         }
 
         // Essayer de valider le token
-        isValid, err := a.keycloakService.ValidateToken(c.Request.Context(), tokenString)
-        if err != nil || !isValid {
+        introspection, err := a.keycloakService.IntrospectToken(c.Request.Context(), tokenString)
+        if err != nil || !introspection.Active {
             // Token invalide, continuer sans authentification
             c.Next()
             return
@@ -104,12 +114,13 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 
         // Token valide, synchroniser l'utilisateur
         user, err := a.userService.SyncUserFromKeycloak(c.Request.Context(), tokenString)
-        if err == nil && user != nil {
+        if err == nil && user != nil && user.CanSignIn() {
             c.Set("user_id", user.ID.String())
             c.Set("keycloak_id", user.KeycloakID)
             c.Set("username", user.Username)
             c.Set("email", user.Email)
             c.Set("user", user)
+            c.Set("roles", introspection.RealmRoles)
         }
 
         c.Next()
@@ -121,14 +132,14 @@ func (a *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
     return func(c *gin.Context) {
         roles, exists := c.Get("roles")
         if !exists {
-            c.JSON(http.StatusForbidden, gin.H{"error": "No roles found"})
+            utils.RespondError(c, http.StatusForbidden, "No roles found")
             c.Abort()
             return
         }
 
         userRoles, ok := roles.([]string)
         if !ok {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Invalid roles format"})
+            utils.RespondError(c, http.StatusForbidden, "Invalid roles format")
             c.Abort()
             return
         }
@@ -142,7 +153,7 @@ func (a *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
         }
 
         if !hasRole {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+            utils.RespondError(c, http.StatusForbidden, "Insufficient permissions")
             c.Abort()
             return
         }
@@ -156,14 +167,14 @@ func (a *AuthMiddleware) RequireAnyRole(requiredRoles ...string) gin.HandlerFunc
     return func(c *gin.Context) {
         roles, exists := c.Get("roles")
         if !exists {
-            c.JSON(http.StatusForbidden, gin.H{"error": "No roles found"})
+            utils.RespondError(c, http.StatusForbidden, "No roles found")
             c.Abort()
             return
         }
 
         userRoles, ok := roles.([]string)
         if !ok {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Invalid roles format"})
+            utils.RespondError(c, http.StatusForbidden, "Invalid roles format")
             c.Abort()
             return
         }
@@ -182,7 +193,7 @@ func (a *AuthMiddleware) RequireAnyRole(requiredRoles ...string) gin.HandlerFunc
         }
 
         if !hasAnyRole {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+            utils.RespondError(c, http.StatusForbidden, "Insufficient permissions")
             c.Abort()
             return
         }
@@ -13,6 +13,8 @@ import (
 
     "github.com/gin-gonic/gin"
     "github.com/golang-jwt/jwt/v5"
+
+    "collabhub-music-backend/pkg/utils"
 )
 
 type JWKSet struct {
@@ -60,14 +62,14 @@ func (j *JWTMiddleware) ValidateJWT() gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+            utils.RespondError(c, http.StatusUnauthorized, "Authorization header required")
             c.Abort()
             return
         }
 
         tokenString := strings.TrimPrefix(authHeader, "Bearer ")
         if tokenString == authHeader {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+            utils.RespondError(c, http.StatusUnauthorized, "Bearer token required")
             c.Abort()
             return
         }
@@ -94,27 +96,27 @@ func (j *JWTMiddleware) ValidateJWT() gin.HandlerFunc {
         })
 
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
+            utils.RespondError(c, http.StatusUnauthorized, "Invalid token: " + err.Error())
             c.Abort()
             return
         }
 
         if !token.Valid {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is not valid"})
+            utils.RespondError(c, http.StatusUnauthorized, "Token is not valid")
             c.Abort()
             return
         }
 
         claims, ok := token.Claims.(*KeycloakClaims)
         if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+            utils.RespondError(c, http.StatusUnauthorized, "Invalid token claims")
             c.Abort()
             return
         }
 
         // Validate token expiration
         if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
+            utils.RespondError(c, http.StatusUnauthorized, "Token expired")
             c.Abort()
             return
         }
@@ -1,31 +1,45 @@
 package config
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Keycloak KeycloakConfig
-	Storage  StorageConfig
-	CORS     CORSConfig
+	Environment string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Keycloak    KeycloakConfig
+	Storage     StorageConfig
+	CORS        CORSConfig
+	Email       EmailConfig
+	Webhooks    WebhookConfig
+	Logging     LoggingConfig
+	Projects    ProjectConfig
 }
 
 // ServerConfig contains server-related configuration
 type ServerConfig struct {
 	Host        string
-	Port        string
+	Port        int
 	GinMode     string
 	SSLEnabled  bool
 	SSLCertPath string
 	SSLKeyPath  string
+	// SSLRedirectPort, when set, serves HTTP on that port redirecting to HTTPS
+	SSLRedirectPort int
+	// CacheMaxAge is how many seconds clients and CDNs may cache public responses
+	CacheMaxAge int
+	// MaxPageSize is the most items any list endpoint returns in one page
+	MaxPageSize int
 }
 
 // DatabaseConfig contains database connection configuration
@@ -52,9 +66,23 @@ type KeycloakConfig struct {
 
 // StorageConfig contains file storage configuration
 type StorageConfig struct {
-	UploadPath   string
-	MaxFileSize  string
-	AllowedTypes []string
+	UploadPath       string
+	MaxFileSize      string
+	MaxZipUploadSize int64 // in bytes
+	MaxZipEntries    int
+	MaxPathDepth     int    // directory levels in an extracted entry name
+	MaxPathLength    int    // bytes in an extracted file's full path
+	AllowSymlinks    bool   // recreate archive symlinks that stay inside the project
+	SkipHidden       bool   // leave out dotfiles and hidden directories of archives
+	VerifyCRC        bool   // read every archive entry on validation to catch corrupted uploads
+	MaxExtractions   int    // archives extracted at once across all requests
+	UploadsPerUser   int    // uploads a user may have in progress at once, 0 for no limit
+	ValidationLimit  int    // archive validation and info requests a user may make per minute
+	ExtractionWait   int    // seconds an extraction waits for a free slot before a 429
+	JobRetention     int    // hours a finished extraction job stays listed
+	MaxAudioFiles    int    // per project, 0 for no limit
+	Layout           string // "flat" or "sharded" (archives by upload date, projects by ID prefix)
+	AllowedTypes     []string
 }
 
 // CORSConfig contains CORS configuration for frontend integration
@@ -65,8 +93,40 @@ type CORSConfig struct {
 	AllowCredentials bool
 }
 
-// Load loads configuration from environment variables and files
-func Load() *Config {
+// EmailConfig contains SMTP configuration for outgoing notifications
+type EmailConfig struct {
+	Enabled     bool
+	SMTPHost    string
+	SMTPPort    int
+	Username    string
+	Password    string
+	FromName    string
+	FromAddress string
+}
+
+// WebhookConfig contains the signing and delivery settings for project webhooks
+type WebhookConfig struct {
+	Secret  string // HMAC key for the X-CollabHub-Signature header
+	Timeout int    // seconds a single delivery may take
+
+	// AllowPrivateNetworks lets webhooks reach loopback and private addresses, for local development
+	AllowPrivateNetworks bool
+}
+
+// LoggingConfig contains request logging configuration
+type LoggingConfig struct {
+	RedactFields []string // field and header names masked in logs; empty uses the middleware defaults
+}
+
+// ProjectConfig contains defaults for project collaboration
+type ProjectConfig struct {
+	DefaultCollaboratorRole string // role given when adding or inviting without one: admin, collaborator or viewer
+}
+
+// Load loads configuration from environment variables and files.
+// In production an invalid configuration is returned as an error so startup
+// stops; in other environments validation problems are only logged.
+func Load() (*Config, error) {
 	// Load from environment file based on GO_ENV
 	env := os.Getenv("GO_ENV")
 	if env == "" {
@@ -83,14 +143,23 @@ func Load() *Config {
 		}
 	}
 
+	port, err := strconv.Atoi(getEnv("SERVER_PORT", "8444"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_PORT %q: must be a number", os.Getenv("SERVER_PORT"))
+	}
+
 	cfg := &Config{
+		Environment: env,
 		Server: ServerConfig{
-			Host:        getEnv("SERVER_HOST", "localhost"),
-			Port:        getEnv("SERVER_PORT", "8444"),
-			GinMode:     getEnv("GIN_MODE", "debug"),
-			SSLEnabled:  getBoolEnv("SSL_ENABLED", false),
-			SSLCertPath: getEnv("SSL_CERT_PATH", "./certs/server.crt"),
-			SSLKeyPath:  getEnv("SSL_KEY_PATH", "./certs/server.key"),
+			Host:            getEnv("SERVER_HOST", "localhost"),
+			Port:            port,
+			GinMode:         getEnv("GIN_MODE", "debug"),
+			SSLEnabled:      getBoolEnv("SSL_ENABLED", false),
+			SSLCertPath:     getEnv("SSL_CERT_PATH", "./certs/server.crt"),
+			SSLKeyPath:      getEnv("SSL_KEY_PATH", "./certs/server.key"),
+			SSLRedirectPort: getIntEnv("SSL_REDIRECT_PORT", 0),
+			CacheMaxAge:     getIntEnv("CACHE_MAX_AGE", 300),
+			MaxPageSize:     getIntEnv("MAX_PAGE_SIZE", 100),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -111,48 +180,194 @@ func Load() *Config {
 			ClientSecret: getEnv("KEYCLOAK_CLIENT_SECRET", ""),
 		},
 		Storage: StorageConfig{
-			UploadPath:   getEnv("UPLOAD_PATH", "./uploads"),
-			MaxFileSize:  getEnv("MAX_FILE_SIZE", "100MB"),
-			AllowedTypes: []string{"audio/*", "image/*", "application/pdf"},
+			UploadPath:       getEnv("UPLOAD_PATH", "./uploads"),
+			MaxFileSize:      getEnv("MAX_FILE_SIZE", "100MB"),
+			MaxZipUploadSize: int64(getIntEnv("MAX_ZIP_UPLOAD_SIZE", 500<<20)),
+			MaxZipEntries:    getIntEnv("MAX_ZIP_ENTRIES", 10000),
+			MaxPathDepth:     getIntEnv("MAX_ZIP_PATH_DEPTH", 32),
+			MaxPathLength:    getIntEnv("MAX_ZIP_PATH_LENGTH", 1024),
+			AllowSymlinks:    getBoolEnv("ALLOW_ZIP_SYMLINKS", false),
+			SkipHidden:       getBoolEnv("SKIP_HIDDEN_ZIP_ENTRIES", true),
+			VerifyCRC:        getBoolEnv("VERIFY_ZIP_CRC", false),
+			MaxExtractions:   getIntEnv("MAX_CONCURRENT_EXTRACTIONS", 4),
+			UploadsPerUser:   getIntEnv("MAX_CONCURRENT_UPLOADS_PER_USER", 2),
+			ValidationLimit:  getIntEnv("ZIP_VALIDATIONS_PER_MINUTE", 30),
+			ExtractionWait:   getIntEnv("EXTRACTION_QUEUE_TIMEOUT", 30),
+			JobRetention:     getIntEnv("JOB_RETENTION_HOURS", 24),
+			MaxAudioFiles:    getIntEnv("MAX_AUDIO_FILES_PER_PROJECT", 0),
+			Layout:           getEnv("STORAGE_LAYOUT", "flat"),
+			AllowedTypes:     []string{"audio/*", "image/*", "application/pdf"},
+		},
+		Email: EmailConfig{
+			Enabled:     getBoolEnv("EMAIL_ENABLED", false),
+			SMTPHost:    getEnv("EMAIL_SMTP_HOST", "localhost"),
+			SMTPPort:    getIntEnv("EMAIL_SMTP_PORT", 587),
+			Username:    getEnv("EMAIL_USERNAME", ""),
+			Password:    getEnv("EMAIL_PASSWORD", ""),
+			FromName:    getEnv("EMAIL_FROM_NAME", "CollabHub Music"),
+			FromAddress: getEnv("EMAIL_FROM_ADDRESS", "noreply@collabhub-music.com"),
 		},
-		CORS: CORSConfig{
-			AllowedOrigins: []string{
-				"http://localhost:8081",  // React Native Metro
-				"http://localhost:3000",  // React Web
-				"https://localhost:3000", // React Web HTTPS
-				"exp://localhost:19000",  // Expo
-				"exp://192.168.*:19000",  // Expo LAN
-				"*",                      // Allow all origins in development
-			},
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-			AllowedHeaders:   []string{"*"},
-			AllowCredentials: true,
+		Webhooks: WebhookConfig{
+			Secret:               getEnv("WEBHOOK_SECRET", ""),
+			Timeout:              getIntEnv("WEBHOOK_TIMEOUT", 10),
+			AllowPrivateNetworks: getBoolEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+		},
+		Logging: LoggingConfig{
+			RedactFields: getSliceEnv("LOG_REDACT_FIELDS", nil),
+		},
+		Projects: ProjectConfig{
+			DefaultCollaboratorRole: getEnv("DEFAULT_COLLABORATOR_ROLE", "viewer"),
 		},
 	}
 
+	cfg.CORS = loadCORSConfig(cfg.IsProduction())
+
 	// Validate configuration
 	if err := validateConfig(cfg); err != nil {
+		if cfg.IsProduction() {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
 		log.Printf("Configuration validation warning: %v", err)
 	}
+	if !cfg.IsProduction() {
+		for _, name := range missingSecrets(cfg) {
+			log.Printf("Warning: %s is not set; it is required in production", name)
+		}
+	}
+
+	return cfg, nil
+}
+
+// loadCORSConfig reads the CORS settings. Development falls back to permissive defaults
+// that include "*"; production has no default origins, so CORS_ALLOWED_ORIGINS must list
+// them explicitly.
+func loadCORSConfig(production bool) CORSConfig {
+	var defaultOrigins []string
+	if !production {
+		defaultOrigins = []string{
+			"http://localhost:8081",  // React Native Metro
+			"http://localhost:3000",  // React Web
+			"https://localhost:3000", // React Web HTTPS
+			"exp://localhost:19000",  // Expo
+			"exp://192.168.*:19000",  // Expo LAN
+			"*",                      // Allow all origins in development
+		}
+	}
+
+	// Browsers don't treat "*" as a wildcard on credentialed requests, so the request
+	// headers clients send are listed
+	return CORSConfig{
+		AllowedOrigins:   getSliceEnv("CORS_ALLOWED_ORIGINS", defaultOrigins),
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowedHeaders:   []string{"Origin", "Accept", "Content-Type", "Content-Length", "Authorization", "X-Request-ID"},
+		AllowCredentials: true,
+	}
+}
+
+// DSN returns the PostgreSQL connection string for the database
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode, d.Timezone)
+}
+
+// IsProduction reports whether the application runs in production,
+// either through GO_ENV=production or GIN_MODE=release
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production" || c.Server.GinMode == "release"
+}
+
+// UseTLS reports whether the server should serve HTTPS. SSL must be enabled and the
+// certificate and key must load as a pair; otherwise the server falls back to HTTP with a
+// warning, except in production where unusable files are an error.
+func (c *Config) UseTLS() (bool, error) {
+	if !c.Server.SSLEnabled {
+		return false, nil
+	}
 
-	return cfg
+	if _, err := tls.LoadX509KeyPair(c.Server.SSLCertPath, c.Server.SSLKeyPath); err != nil {
+		if c.IsProduction() {
+			return false, fmt.Errorf("SSL is enabled but unusable: %w", err)
+		}
+		log.Println("Warning: SSL is enabled but unusable, falling back to HTTP:", err)
+		return false, nil
+	}
+	return true, nil
 }
 
-// validateConfig validates the configuration
+// validateConfig validates the configuration, applying the stricter
+// production rules when running in production
 func validateConfig(cfg *Config) error {
-	if cfg.Server.Port == "" {
-		return fmt.Errorf("server port is required")
+	var errs []error
+
+	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server port must be between 1 and 65535"))
 	}
 
 	if cfg.Database.Host == "" {
-		return fmt.Errorf("database host is required")
+		errs = append(errs, fmt.Errorf("database host is required"))
 	}
 
 	if cfg.Keycloak.URL == "" {
-		return fmt.Errorf("keycloak URL is required")
+		errs = append(errs, fmt.Errorf("keycloak URL is required"))
+	}
+
+	if cfg.Storage.Layout != "flat" && cfg.Storage.Layout != "sharded" {
+		errs = append(errs, fmt.Errorf("STORAGE_LAYOUT must be flat or sharded"))
+	}
+
+	if cfg.Storage.ValidationLimit <= 0 {
+		errs = append(errs, fmt.Errorf("ZIP_VALIDATIONS_PER_MINUTE must be at least 1"))
+	}
+
+	switch cfg.Projects.DefaultCollaboratorRole {
+	case "admin", "collaborator", "viewer":
+	default:
+		errs = append(errs, fmt.Errorf("DEFAULT_COLLABORATOR_ROLE must be admin, collaborator or viewer"))
+	}
+
+	if cfg.IsProduction() {
+		errs = append(errs, validateProductionConfig(cfg)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateProductionConfig checks the settings that must be explicit in production
+func validateProductionConfig(cfg *Config) []error {
+	var errs []error
+
+	for _, name := range missingSecrets(cfg) {
+		errs = append(errs, fmt.Errorf("%s is required in production", name))
 	}
 
-	return nil
+	if len(cfg.CORS.AllowedOrigins) == 0 {
+		errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS is required in production"))
+	}
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		if strings.Contains(origin, "*") {
+			errs = append(errs, fmt.Errorf("wildcard CORS origin %q is not allowed in production", origin))
+		}
+	}
+
+	if !cfg.Server.SSLEnabled {
+		errs = append(errs, fmt.Errorf("SSL_ENABLED must be true in production"))
+	} else if cfg.Server.SSLCertPath == "" || cfg.Server.SSLKeyPath == "" {
+		errs = append(errs, fmt.Errorf("SSL_CERT_PATH and SSL_KEY_PATH are required in production"))
+	}
+
+	return errs
+}
+
+// missingSecrets lists the environment variables of the secrets that are not set
+func missingSecrets(cfg *Config) []string {
+	var missing []string
+	if cfg.Keycloak.ClientSecret == "" {
+		missing = append(missing, "KEYCLOAK_CLIENT_SECRET")
+	}
+	if cfg.Database.Password == "" {
+		missing = append(missing, "DB_PASSWORD")
+	}
+	return missing
 }
 
 // Helper functions for environment variables
@@ -180,3 +395,18 @@ func getIntEnv(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getSliceEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
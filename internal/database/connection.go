@@ -1,6 +1,7 @@
 package database
 
 import (
+    "context"
     "fmt"
     "time"
 
@@ -29,4 +30,13 @@ func InitDB(databaseURL string) (*gorm.DB, error) {
     sqlDB.SetConnMaxLifetime(time.Hour)
 
     return db, nil
-}
\ No newline at end of file
+}
+
+// Ping checks that the database is reachable
+func Ping(ctx context.Context, db *gorm.DB) error {
+    sqlDB, err := db.DB()
+    if err != nil {
+        return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+    }
+    return sqlDB.PingContext(ctx)
+}
@@ -13,10 +13,14 @@ func RunMigrations(db *gorm.DB) error {
         &models.User{},
         &models.Project{},
         &models.ProjectCollaborator{},
+        &models.UserProjectFavorite{},
         &models.Branch{},
         &models.File{},
         &models.FileVersion{},
         &models.AudioMetadata{},
+        &models.FileUpload{},
+        &models.Track{},
+        &models.Comment{},
     )
     if err != nil {
         return fmt.Errorf("failed to run migrations: %w", err)
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/pkg/logger"
+    "collabhub-music-backend/pkg/utils"
+
+    "github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles operations reserved to support staff
+type AdminHandler struct {
+    zipService *services.ZipService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(zipService *services.ZipService) *AdminHandler {
+    return &AdminHandler{
+        zipService: zipService,
+    }
+}
+
+// GetProjectStorage godoc
+// @Summary Inspect project storage
+// @Description List the extracted files and archives a project keeps on disk, with their total size. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.APIResponse{data=models.ProjectStorage} "Project storage"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Admin role required"
+// @Failure 404 {object} utils.APIError "Project not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /admin/projects/{id}/storage [get]
+func (h *AdminHandler) GetProjectStorage(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    storage, err := h.zipService.ProjectStorage(projectID)
+    if err != nil {
+        if errors.Is(err, services.ErrNotFound) {
+            utils.RespondError(c, http.StatusNotFound, "Project not found")
+            return
+        }
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to inspect project storage")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to inspect project storage")
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(storage))
+}
+
+// PurgeProjectStorage godoc
+// @Summary Purge project storage
+// @Description Remove a project's extracted files and archives from disk to reclaim space. The project and its file records are kept and flagged with storage_purged. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.APIResponse{data=models.ProjectStorage} "Storage that was removed"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Admin role required"
+// @Failure 404 {object} utils.APIError "Project not found"
+// @Failure 409 {object} utils.APIError "An archive of the project is being extracted"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /admin/projects/{id}/storage [delete]
+func (h *AdminHandler) PurgeProjectStorage(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    storage, err := h.zipService.PurgeProjectStorage(projectID)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project not found")
+        case errors.Is(err, services.ErrConflict):
+            utils.RespondError(c, http.StatusConflict, "An archive of the project is being extracted")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to purge project storage")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to purge project storage")
+        }
+        return
+    }
+
+    logger.FromContext(c.Request.Context()).WithField("project_id", projectID).Info("Project storage purged")
+    c.JSON(http.StatusOK, utils.SuccessResponse(storage))
+}
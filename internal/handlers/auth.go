@@ -1,18 +1,63 @@
 ﻿package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+    "errors"
+    "net/http"
 
-type AuthHandler struct{}
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/models"
+    "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/pkg/logger"
+    "collabhub-music-backend/pkg/utils"
 
-func NewAuthHandler() *AuthHandler {
-    return &AuthHandler{}
+    "github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+    users *services.UserService
+}
+
+func NewAuthHandler(users *services.UserService) *AuthHandler {
+    return &AuthHandler{
+        users: users,
+    }
 }
 
+// Login godoc
+// @Summary Log in
+// @Description Sign in with a username, or email, and password. The response carries the Keycloak tokens and the signed-in user, who is created on their first login.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body models.LoginRequest true "Credentials"
+// @Success 200 {object} utils.APIResponse{data=models.LoginResponse} "Tokens and user"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 401 {object} utils.APIError "Invalid username or password"
+// @Failure 403 {object} utils.APIError "Account deactivated or deleted"
+// @Failure 422 {object} utils.APIError "Missing username or password"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
-    c.JSON(200, map[string]interface{}{
-        "success": true,
-        "message": "login endpoint",
-    })
+    var req models.LoginRequest
+    if !middleware.BindJSON(c, &req) {
+        return
+    }
+
+    login, err := h.users.Login(c.Request.Context(), req.Username, req.Password)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrInvalidCredentials):
+            utils.RespondError(c, http.StatusUnauthorized, "Invalid username or password")
+        case errors.Is(err, services.ErrAccountInactive):
+            utils.RespondErrorWithCode(c, http.StatusForbidden, utils.ErrCodeAccountInactive, "This account has been deactivated or deleted")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to log in")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to log in")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(login))
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "mime"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strconv"
+
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/models"
+    "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/pkg/logger"
+    "collabhub-music-backend/pkg/utils"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// FileHandler handles operations on stored project files
+type FileHandler struct {
+    fileService *services.FileService
+}
+
+// NewFileHandler creates a new file handler
+func NewFileHandler(fileService *services.FileService) *FileHandler {
+    return &FileHandler{
+        fileService: fileService,
+    }
+}
+
+// ReprocessProject godoc
+// @Summary Reprocess project files
+// @Description Re-read the extracted files of a project, recomputing checksums and audio metadata and updating the stored rows. Files without a record are added to the default branch. Safe to run repeatedly. Viewers can't reprocess a project.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID"
+// @Success 200 {object} utils.APIResponse{data=models.ReprocessResult} "Reprocessing summary"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not allowed to edit the project's files"
+// @Failure 404 {object} utils.APIError "Project not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/projects/{project_id}/reprocess [post]
+func (h *FileHandler) ReprocessProject(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    result, err := h.fileService.ReprocessProject(userID, projectID)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project files not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not allowed to edit the files of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to reprocess project files")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to reprocess project files")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// GetFileTree godoc
+// @Summary Get a project's file tree
+// @Description Get the files of a project branch arranged into folders, with each folder's total size. The default branch is used unless branch_id is given. With audio_only=true only audio files are kept, and folders without any are left out, so a player can show just the playable content. Only members of the project can read it.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID"
+// @Param branch_id query string false "Branch ID, defaults to the project's default branch"
+// @Param audio_only query bool false "Keep only audio files and the folders that hold them"
+// @Success 200 {object} utils.APIResponse{data=[]models.FileTreeNode} "Top-level folders and files"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "Project or branch not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/projects/{project_id}/tree [get]
+func (h *FileHandler) GetFileTree(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
+        return
+    }
+
+    branchID := uuid.Nil
+    if branchIDStr := c.Query("branch_id"); branchIDStr != "" {
+        parsedID, err := uuid.Parse(branchIDStr)
+        if err != nil {
+            utils.RespondError(c, http.StatusBadRequest, "Invalid branch ID format")
+            return
+        }
+        branchID = parsedID
+    }
+    audioOnly, _ := strconv.ParseBool(c.Query("audio_only"))
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    tree, err := h.fileService.BuildFileTree(c.Request.Context(), userID, projectID, branchID, audioOnly)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project or branch not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to build file tree")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to build file tree")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(tree))
+}
+
+// BulkUpdateMetadata godoc
+// @Summary Tag several files at once
+// @Description Set title, artist, album, year or track number on the audio files at the given paths of the project's default branch, in one transaction. Only the fields given are changed. Paths that aren't audio files of the branch are returned as skipped. Viewers can't tag files.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID"
+// @Param request body models.BulkMetadataRequest true "Relative file paths (max 100) and the fields to set"
+// @Success 200 {object} utils.APIResponse{data=models.BulkMetadataResult} "Number of files updated"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not allowed to edit the project's files"
+// @Failure 404 {object} utils.APIError "Project or default branch not found"
+// @Failure 422 {object} utils.APIError "Validation failed"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/projects/{project_id}/metadata/bulk [post]
+func (h *FileHandler) BulkUpdateMetadata(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
+        return
+    }
+
+    var req models.BulkMetadataRequest
+    if !middleware.BindJSON(c, &req) {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    result, err := h.fileService.BulkUpdateMetadata(c.Request.Context(), userID, projectID, &req)
+    if err != nil {
+        var fieldErr *services.FieldError
+        switch {
+        case errors.As(err, &fieldErr):
+            utils.RespondValidationError(c, "Validation failed", []*services.FieldError{fieldErr})
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project or default branch not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not allowed to edit the files of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to update file metadata")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to update file metadata")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// ResyncProjectFiles godoc
+// @Summary Resync project files
+// @Description Reconcile the file records of the project's default branch with the extracted files on disk: files without a record get one and records whose file is gone are deleted, in one transaction. Viewers can't resync a project.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID"
+// @Success 200 {object} utils.APIResponse{data=models.ResyncResult} "Reconciliation summary"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not allowed to edit the project's files"
+// @Failure 404 {object} utils.APIError "Project files or default branch not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/projects/{project_id}/resync-files [post]
+func (h *FileHandler) ResyncProjectFiles(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    result, err := h.fileService.ResyncProjectFiles(userID, projectID)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project files or default branch not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not allowed to edit the files of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to resync project files")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to resync project files")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// DownloadFile godoc
+// @Summary Download a file
+// @Description Stream the content of a stored file. Only members of the file's project can download it. HEAD returns the same headers (including Content-Length) without a body, and Range requests are supported. With verify=true the content is checked against the stored checksum before it is sent.
+// @Tags Files
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Param verify query bool false "Verify the content against the stored checksum first (reads the whole file)"
+// @Success 200 {file} binary "File content"
+// @Success 206 {file} binary "Partial file content"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "File not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/{id}/download [get]
+// @Router /files/{id}/download [head]
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+    fileID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    file, content, err := h.fileService.OpenFileContent(c.Request.Context(), userID, fileID)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "File not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to open file")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to open file")
+        }
+        return
+    }
+    defer content.Close()
+
+    if verify, _ := strconv.ParseBool(c.Query("verify")); verify {
+        if err := h.fileService.VerifyContent(file, content); err != nil {
+            entry := logger.FromContext(c.Request.Context()).WithError(err).WithField("file_id", file.ID)
+            if errors.Is(err, services.ErrChecksumMismatch) {
+                entry.WithField("storage_path", file.StoragePath).Error("Stored file is corrupted")
+                utils.RespondError(c, http.StatusInternalServerError, "File integrity check failed: content does not match its checksum")
+                return
+            }
+            entry.Error("Failed to verify file")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to verify file")
+            return
+        }
+    }
+
+    serveContent(c, file.Name, file.MimeType, content)
+}
+
+// DownloadVersion godoc
+// @Summary Download a file version
+// @Description Stream the content of one stored version of a file. Only members of the file's project can download it. HEAD and Range requests are supported.
+// @Tags Files
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Param version path int true "Version number"
+// @Success 200 {file} binary "Version content"
+// @Success 206 {file} binary "Partial version content"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "File or version not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/{id}/versions/{version}/download [get]
+// @Router /files/{id}/versions/{version}/download [head]
+func (h *FileHandler) DownloadVersion(c *gin.Context) {
+    fileID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+    version, err := strconv.Atoi(c.Param("version"))
+    if err != nil {
+        utils.RespondError(c, http.StatusBadRequest, "Invalid version number")
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    file, _, content, err := h.fileService.OpenVersionContent(c.Request.Context(), userID, fileID, version)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "File or version not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to open file version")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to open file version")
+        }
+        return
+    }
+    defer content.Close()
+
+    serveContent(c, file.Name, file.MimeType, content)
+}
+
+// GetFileMetadata godoc
+// @Summary Get a file's audio metadata
+// @Description Get the audio metadata read from a stored file, such as its duration, BPM, key and tags. Files that haven't been analyzed, including files that aren't audio, have has_metadata false and no metadata. Only members of the file's project can read it.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Success 200 {object} utils.APIResponse{data=models.FileMetadata} "Audio metadata of the file"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "File not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/{id}/metadata [get]
+func (h *FileHandler) GetFileMetadata(c *gin.Context) {
+    fileID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    metadata, err := h.fileService.GetFileMetadata(c.Request.Context(), userID, fileID)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "File not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to get file metadata")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to get file metadata")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(metadata))
+}
+
+// serveContent streams stored content as an attachment. The content type falls back to
+// one guessed from the name.
+func serveContent(c *gin.Context, name, contentType string, content *os.File) {
+    stat, err := content.Stat()
+    if err != nil {
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to read file")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to read file")
+        return
+    }
+
+    if contentType == "" {
+        contentType = mime.TypeByExtension(filepath.Ext(name))
+    }
+    if contentType != "" {
+        c.Header("Content-Type", contentType)
+    }
+    c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+
+    // ServeContent sets Content-Length, handles Range and writes no body for HEAD
+    http.ServeContent(c.Writer, c.Request, name, stat.ModTime(), content)
+}
+
+// DiffVersions godoc
+// @Summary Compare two file versions
+// @Description Return the differences in size, checksum and version metadata between two versions of a file. Audio content is not compared. Only members of the file's project can compare its versions.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Param from query int true "Version number to compare from"
+// @Param to query int true "Version number to compare to"
+// @Success 200 {object} utils.APIResponse{data=models.FileVersionDiff} "Version differences"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "File or version not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/{id}/versions/diff [get]
+func (h *FileHandler) DiffVersions(c *gin.Context) {
+    fileID, ok := middleware.ParamUUID(c, "id")
+    if !ok {
+        return
+    }
+
+    from, err := strconv.Atoi(c.Query("from"))
+    if err != nil {
+        utils.RespondError(c, http.StatusBadRequest, "Invalid or missing from version")
+        return
+    }
+    to, err := strconv.Atoi(c.Query("to"))
+    if err != nil {
+        utils.RespondError(c, http.StatusBadRequest, "Invalid or missing to version")
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    diff, err := h.fileService.DiffVersions(c.Request.Context(), userID, fileID, from, to)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "File or version not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to compare versions")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to compare versions")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(diff))
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "sort"
+    "time"
+
+    "collabhub-music-backend/pkg/utils"
+
+    "github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds how long a single dependency check may take
+const readinessTimeout = 3 * time.Second
+
+// HealthCheck reports whether a dependency is reachable
+type HealthCheck func(ctx context.Context) error
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+    checks map[string]HealthCheck
+}
+
+// NewHealthHandler creates a health handler; checks are the dependencies, by name,
+// that must be reachable for the server to be ready
+func NewHealthHandler(checks map[string]HealthCheck) *HealthHandler {
+    return &HealthHandler{
+        checks: checks,
+    }
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Report that the process is running. Dependencies are not checked, so a failure means the process should be restarted.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} utils.APIResponse "Process is running"
+// @Router /health/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+    c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+        "status": "ok",
+    }))
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Report whether the server can serve requests, i.e. the database and Keycloak are reachable. Returns 503 with the failing checks otherwise.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} utils.APIResponse "Ready to serve requests"
+// @Failure 503 {object} utils.APIResponse "A dependency is unreachable"
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+    names := make([]string, 0, len(h.checks))
+    for name := range h.checks {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    ready := true
+    results := make(map[string]string, len(h.checks))
+    for _, name := range names {
+        ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+        err := h.checks[name](ctx)
+        cancel()
+
+        if err != nil {
+            ready = false
+            results[name] = err.Error()
+            continue
+        }
+        results[name] = "ok"
+    }
+
+    if !ready {
+        c.JSON(http.StatusServiceUnavailable, utils.APIResponse{
+            Status:  "error",
+            Data:    gin.H{"status": "unavailable", "checks": results},
+            Message: "Service is not ready",
+        })
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+        "status": "ready",
+        "checks": results,
+    }))
+}
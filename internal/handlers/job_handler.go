@@ -0,0 +1,146 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "time"
+
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/models"
+    "collabhub-music-backend/internal/services"
+    "collabhub-music-backend/pkg/logger"
+    "collabhub-music-backend/pkg/utils"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// JobHandler handles background job operations
+type JobHandler struct {
+    jobs *services.JobManager
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobs *services.JobManager) *JobHandler {
+    return &JobHandler{
+        jobs: jobs,
+    }
+}
+
+// ListJobs godoc
+// @Summary List my jobs
+// @Description List the current user's background extraction jobs, newest first. Other users' jobs are never included.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only jobs in this status (pending, running, completed, failed, canceled)"
+// @Success 200 {object} utils.APIResponse{data=[]models.ExtractionJob} "Jobs of the current user"
+// @Failure 400 {object} utils.APIError "Unknown status"
+// @Router /files/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+    status := models.JobStatus(c.Query("status"))
+    if status != "" && !status.Valid() {
+        utils.RespondError(c, http.StatusBadRequest, "Unknown job status")
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    c.JSON(http.StatusOK, utils.SuccessResponse(h.jobs.ListJobs(userID, status)))
+}
+
+// CancelJob godoc
+// @Summary Cancel a job
+// @Description Cancel one of the current user's pending or running extraction jobs. Files it already extracted are removed before the response is sent.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} utils.APIResponse{data=models.ExtractionJob} "Job after cancelation"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 404 {object} utils.APIError "Job not found"
+// @Failure 409 {object} utils.APIError "Job has already finished"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/jobs/{job_id} [delete]
+func (h *JobHandler) CancelJob(c *gin.Context) {
+    jobID, ok := middleware.ParamUUID(c, "job_id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    job, err := h.jobs.CancelJob(c.Request.Context(), userID, jobID)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Job not found")
+        case errors.Is(err, services.ErrConflict):
+            utils.RespondError(c, http.StatusConflict, "Job has already finished")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to cancel job")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to cancel job")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(job))
+}
+
+// JobEvents godoc
+// @Summary Stream job events
+// @Description Stream the progress of one of the current user's extraction jobs as Server-Sent Events. Each event carries the job; "progress" events are sent while it is pending or running, then a single "completed", "failed" or "canceled" event, after which the stream ends. A finished job gets its final event right away.
+// @Tags Files
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} models.ExtractionJob "Stream of job events"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 404 {object} utils.APIError "Job not found"
+// @Router /files/jobs/{job_id}/events [get]
+func (h *JobHandler) JobEvents(c *gin.Context) {
+    jobID, ok := middleware.ParamUUID(c, "job_id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    job, updates, stop, err := h.jobs.WatchJob(userID, jobID)
+    if err != nil {
+        utils.RespondError(c, http.StatusNotFound, "Job not found")
+        return
+    }
+    defer stop()
+
+    // The stream lasts as long as the job, well past the server's write timeout
+    _ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+    c.Header("Content-Type", "text/event-stream")
+    c.Header("X-Accel-Buffering", "no")
+    c.Status(http.StatusOK)
+
+    for {
+        writeJobEvent(c, job)
+        if job.Status.Finished() {
+            return
+        }
+
+        select {
+        case <-c.Request.Context().Done():
+            // The client went away; stop lets go of the job
+            return
+        case update, open := <-updates:
+            if !open {
+                return
+            }
+            job = update
+        }
+    }
+}
+
+// writeJobEvent sends a job as a Server-Sent Event named after its progress
+func writeJobEvent(c *gin.Context, job models.ExtractionJob) {
+    event := "progress"
+    if job.Status.Finished() {
+        event = string(job.Status)
+    }
+    c.SSEvent(event, job)
+    c.Writer.Flush()
+}
@@ -1,31 +1,67 @@
 package handlers
 
 import (
+    "errors"
     "fmt"
+    "io"
+    "mime/multipart"
     "net/http"
+    "os"
     "path/filepath"
     "strconv"
+    "time"
 
+    "collabhub-music-backend/internal/middleware"
     "collabhub-music-backend/internal/models"
     "collabhub-music-backend/internal/services"
+    apiutils "collabhub-music-backend/internal/utils"
+    "collabhub-music-backend/pkg/logger"
     "collabhub-music-backend/pkg/utils"
 
     "github.com/gin-gonic/gin"
     "github.com/google/uuid"
 )
 
+// multipartOverhead is the allowance for multipart boundaries and headers on top of the file size
+const multipartOverhead = 1 << 20 // 1MB
+
+// maxBatchValidateFiles caps the number of archives in one batch validation request
+const maxBatchValidateFiles = 20
+
+// errUploadTooLarge is returned when an upload exceeds the configured size limit
+var errUploadTooLarge = errors.New("upload exceeds size limit")
+
 // ZipHandler handles ZIP file operations
 type ZipHandler struct {
-    zipService *services.ZipService
+    zipService    *services.ZipService
+    jobs          *services.JobManager
+    maxUploadSize int64
 }
 
-// NewZipHandler creates a new ZIP handler
-func NewZipHandler(zipService *services.ZipService) *ZipHandler {
+// NewZipHandler creates a new ZIP handler; maxUploadSize is the largest accepted ZIP in bytes.
+// Without a job manager, extractions can only run within the request.
+func NewZipHandler(zipService *services.ZipService, jobs *services.JobManager, maxUploadSize int64) *ZipHandler {
     return &ZipHandler{
-        zipService: zipService,
+        zipService:    zipService,
+        jobs:          jobs,
+        maxUploadSize: maxUploadSize,
     }
 }
 
+// GetCapabilities godoc
+// @Summary Get upload capabilities
+// @Description Return the supported audio extensions and the upload limits, so clients don't have to hardcode them
+// @Tags Files
+// @Produce json
+// @Success 200 {object} utils.APIResponse{data=models.UploadCapabilities} "Supported formats and limits"
+// @Router /files/capabilities [get]
+func (h *ZipHandler) GetCapabilities(c *gin.Context) {
+    capabilities := h.zipService.Capabilities()
+    capabilities.MaxUploadSize = h.maxUploadSize
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(capabilities))
+}
+
 // UploadZip godoc
 // @Summary Upload and validate ZIP file
 // @Description Upload a ZIP file and validate its contents for audio files
@@ -38,51 +74,106 @@ func NewZipHandler(zipService *services.ZipService) *ZipHandler {
 // @Failure 400 {object} utils.APIError "Bad request - invalid file"
 // @Failure 413 {object} utils.APIError "File too large"
 // @Failure 422 {object} utils.APIError "Validation failed"
+// @Failure 429 {object} utils.APIError "Too many uploads of the current user in progress"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/zip/upload [post]
 func (h *ZipHandler) UploadZip(c *gin.Context) {
+    tooLarge := fmt.Sprintf("File size exceeds %dMB limit", h.maxUploadSize>>20)
+
+    // Reject early when the declared body is already too large, and cap what is actually read
+    // so a lying Content-Length can't stream more than the limit
+    bodyLimit := h.maxUploadSize + multipartOverhead
+    if c.Request.ContentLength > bodyLimit {
+        utils.RespondError(c, http.StatusRequestEntityTooLarge, tooLarge)
+        return
+    }
+    c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, bodyLimit)
+
     // Get uploaded file
     file, err := c.FormFile("file")
     if err != nil {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-            "No file uploaded",
-        ))
+        var maxBytesErr *http.MaxBytesError
+        if errors.As(err, &maxBytesErr) {
+            utils.RespondError(c, http.StatusRequestEntityTooLarge, tooLarge)
+            return
+        }
+        utils.RespondError(c, http.StatusBadRequest, "No file uploaded")
         return
     }
 
     // Validate file type
     if filepath.Ext(file.Filename) != ".zip" {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("File must be a ZIP archive"))
+        utils.RespondError(c, http.StatusBadRequest, "File must be a ZIP archive")
         return
     }
 
-    // Check file size (500MB limit)
-    maxSize := int64(500 * 1024 * 1024) // 500MB
-    if file.Size > maxSize {
-        c.JSON(http.StatusRequestEntityTooLarge, utils.ErrorResponse("File size exceeds 500MB limit"))
+    // Check the declared part size before writing anything
+    if file.Size > h.maxUploadSize {
+        utils.RespondError(c, http.StatusRequestEntityTooLarge, tooLarge)
+        return
+    }
+
+    // Validate ZIP contents straight from the uploaded part, so invalid archives are never saved
+    src, err := file.Open()
+    if err != nil {
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to read uploaded file")
+        return
+    }
+    if err := h.zipService.CheckZipSignature(src); err != nil {
+        src.Close()
+        switch {
+        case errors.Is(err, services.ErrEmptyZipArchive):
+            utils.RespondErrorWithCode(c, http.StatusUnprocessableEntity, utils.ErrCodeInvalidArchive, "ZIP archive is empty")
+        case errors.Is(err, services.ErrNotZipArchive):
+            utils.RespondErrorWithCode(c, http.StatusUnprocessableEntity, utils.ErrCodeInvalidArchive, "File is not a valid ZIP archive")
+        default:
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to read uploaded file")
+        }
+        return
+    }
+    validation, err := h.zipService.ValidateZipReader(src, file.Size)
+    src.Close()
+    if err != nil {
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to validate ZIP file")
+        return
+    }
+
+    if !validation.IsValid {
+        utils.RespondErrorWithCode(c, http.StatusUnprocessableEntity, utils.ErrCodeInvalidArchive, validation.Error)
         return
     }
 
     // Generate unique filename
     fileID := uuid.New()
     filename := fmt.Sprintf("%s_%s", fileID.String(), file.Filename)
-    uploadPath := filepath.Join("uploads", "zips", filename)
+    uploadPath := h.zipService.NewUploadPath(filename)
 
     // Save uploaded file
-    if err := c.SaveUploadedFile(file, uploadPath); err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to save uploaded file"))
+    if err := saveUploadedFile(file, uploadPath, h.maxUploadSize); err != nil {
+        if errors.Is(err, errUploadTooLarge) {
+            utils.RespondError(c, http.StatusRequestEntityTooLarge, tooLarge)
+            return
+        }
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to save uploaded file")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to save uploaded file")
         return
     }
 
-    // Validate ZIP contents
-    validation, err := h.zipService.ValidateZip(uploadPath)
+    // Remember who uploaded the archive so only they can delete it
+    uploaderID, _ := uuid.Parse(c.GetString("user_id"))
+    err = h.zipService.RecordUpload(&models.FileUpload{
+        ID:           fileID,
+        Filename:     filename,
+        OriginalName: file.Filename,
+        ContentType:  "application/zip",
+        Size:         file.Size,
+        Path:         uploadPath,
+        UserID:       uploaderID,
+    })
     if err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to validate ZIP file"))
-        return
-    }
-
-    if !validation.IsValid {
-        c.JSON(http.StatusUnprocessableEntity, utils.ErrorResponse(validation.Error))
+        os.Remove(uploadPath)
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to record upload")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to save uploaded file")
         return
     }
 
@@ -100,6 +191,275 @@ func (h *ZipHandler) UploadZip(c *gin.Context) {
     c.JSON(http.StatusOK, utils.SuccessResponse(response))
 }
 
+// ListUploads godoc
+// @Summary List my uploads
+// @Description List the ZIP archives the current user uploaded, newest first, so earlier work can be picked up again
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Items per page (default 20, max 100)"
+// @Success 200 {object} utils.APIResponse{data=[]models.UploadSummary} "Uploads of the current user"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/zip/uploads [get]
+func (h *ZipHandler) ListUploads(c *gin.Context) {
+    page := apiutils.ParsePaginationParams(c)
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+
+    uploads, total, err := h.zipService.ListUploads(userID, page.Limit, page.Offset)
+    if err != nil {
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to list uploads")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to list uploads")
+        return
+    }
+
+    summaries := make([]models.UploadSummary, 0, len(uploads))
+    for _, upload := range uploads {
+        summaries = append(summaries, models.UploadSummary{
+            ID:           upload.ID,
+            OriginalName: upload.OriginalName,
+            Size:         upload.Size,
+            IsExtracted:  upload.IsExtracted,
+            ProjectID:    upload.ProjectID,
+            CreatedAt:    upload.CreatedAt,
+        })
+    }
+
+    c.JSON(http.StatusOK, utils.PaginatedResponse(summaries, page.Limit, page.Offset, int(total)))
+}
+
+// ValidateZipBatch godoc
+// @Summary Validate several ZIP files
+// @Description Validate up to 20 ZIP files in one request without saving them. Each archive gets its own result, so invalid archives don't fail the batch. The whole request is subject to the single upload size limit.
+// @Tags Files
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param files formData file true "ZIP files to validate (repeat the field for each file)"
+// @Success 200 {object} utils.APIResponse{data=models.ZipBatchValidationResult} "Per-archive results and summary"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 413 {object} utils.APIError "Files too large"
+// @Router /files/zip/validate-batch [post]
+func (h *ZipHandler) ValidateZipBatch(c *gin.Context) {
+    tooLarge := fmt.Sprintf("Files exceed %dMB limit", h.maxUploadSize>>20)
+
+    bodyLimit := h.maxUploadSize + multipartOverhead
+    if c.Request.ContentLength > bodyLimit {
+        utils.RespondError(c, http.StatusRequestEntityTooLarge, tooLarge)
+        return
+    }
+    c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, bodyLimit)
+
+    form, err := c.MultipartForm()
+    if err != nil {
+        var maxBytesErr *http.MaxBytesError
+        if errors.As(err, &maxBytesErr) {
+            utils.RespondError(c, http.StatusRequestEntityTooLarge, tooLarge)
+            return
+        }
+        utils.RespondError(c, http.StatusBadRequest, "Invalid multipart form")
+        return
+    }
+
+    files := form.File["files"]
+    if len(files) == 0 {
+        utils.RespondError(c, http.StatusBadRequest, "No files uploaded")
+        return
+    }
+    if len(files) > maxBatchValidateFiles {
+        utils.RespondError(c, http.StatusBadRequest,
+            fmt.Sprintf("Too many files, at most %d can be validated at once", maxBatchValidateFiles),
+        )
+        return
+    }
+
+    result := models.ZipBatchValidationResult{
+        Archives: make([]models.ZipBatchValidationItem, 0, len(files)),
+    }
+    for _, file := range files {
+        validation := h.validateBatchFile(file)
+
+        result.Archives = append(result.Archives, models.ZipBatchValidationItem{
+            Filename: file.Filename,
+            Result:   validation,
+        })
+        result.Summary.TotalArchives++
+        if validation.IsValid {
+            result.Summary.ValidArchives++
+            result.Summary.AudioFiles += validation.AudioFiles
+        } else {
+            result.Summary.InvalidArchives++
+        }
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// checkAudioFileLimit writes a 422 response and returns false when adding audio files
+// would take the project over its limit
+func (h *ZipHandler) checkAudioFileLimit(c *gin.Context, projectID uuid.UUID, adding int) bool {
+    err := h.zipService.CheckAudioFileLimit(projectID, adding)
+    if err == nil {
+        return true
+    }
+    if h.respondAudioFileLimit(c, err) {
+        return false
+    }
+
+    utils.RespondError(c, http.StatusInternalServerError, "Failed to check audio file limit")
+    return false
+}
+
+// respondAudioFileLimit writes a 422 response and returns true when err is an
+// *services.AudioFileLimitError
+func (h *ZipHandler) respondAudioFileLimit(c *gin.Context, err error) bool {
+    var limitErr *services.AudioFileLimitError
+    if !errors.As(err, &limitErr) {
+        return false
+    }
+    utils.RespondErrorWithCode(c, http.StatusUnprocessableEntity, utils.ErrCodeAudioFileLimit,
+        fmt.Sprintf("Too many audio files: %d, projects are limited to %d", limitErr.AudioFiles, limitErr.Limit),
+    )
+    return true
+}
+
+// extractionRetryAfter is the wait suggested to clients when the extraction pool is full
+const extractionRetryAfter = 10 * time.Second
+
+// respondExtractionQueueFull writes a 429 response and returns true when the extraction
+// failed because no extraction slot freed up in time
+func (h *ZipHandler) respondExtractionQueueFull(c *gin.Context, err error) bool {
+    if !errors.Is(err, services.ErrExtractionQueueFull) {
+        return false
+    }
+    middleware.SetRetryAfter(c, extractionRetryAfter)
+    utils.RespondErrorWithCode(c, http.StatusTooManyRequests, utils.ErrCodeExtractionQueueFull,
+        "Too many extractions in progress, try again later",
+    )
+    return true
+}
+
+// respondProjectAccessError writes the response for a project the user can't see (404) or
+// may not act on this way (403), and a 500 for any other error
+func (h *ZipHandler) respondProjectAccessError(c *gin.Context, err error, failure string) {
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        utils.RespondError(c, http.StatusNotFound, "Project not found")
+    case errors.Is(err, services.ErrForbidden):
+        utils.RespondError(c, http.StatusForbidden, "Not allowed to edit the files of this project")
+    default:
+        logger.FromContext(c.Request.Context()).WithError(err).Error(failure)
+        utils.RespondError(c, http.StatusInternalServerError, failure)
+    }
+}
+
+// findUpload resolves the stored path of an uploaded archive, writing the error
+// response and returning false when it can't
+func (h *ZipHandler) findUpload(c *gin.Context, fileID string) (string, bool) {
+    id, err := uuid.Parse(fileID)
+    if err != nil {
+        utils.RespondError(c, http.StatusBadRequest, "Invalid file ID format")
+        return "", false
+    }
+
+    zipPath, err := h.zipService.UploadPath(id)
+    if err != nil {
+        if errors.Is(err, services.ErrNotFound) {
+            utils.RespondError(c, http.StatusNotFound, "ZIP file not found")
+        } else {
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to look up ZIP file")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to look up ZIP file")
+        }
+        return "", false
+    }
+    return zipPath, true
+}
+
+// markExtracted records on the upload that it was extracted into the project. The
+// extraction has succeeded either way, so a failure is only logged.
+func (h *ZipHandler) markExtracted(c *gin.Context, fileID string, projectID uuid.UUID) {
+    id, _ := uuid.Parse(fileID)
+    if err := h.zipService.MarkExtracted(id, projectID); err != nil {
+        logger.FromContext(c.Request.Context()).WithError(err).Warn("Failed to mark upload as extracted")
+    }
+}
+
+// validateBatchFile validates one archive of a batch, reporting every failure in the result
+func (h *ZipHandler) validateBatchFile(file *multipart.FileHeader) *models.ZipValidationResult {
+    invalid := func(message string) *models.ZipValidationResult {
+        return &models.ZipValidationResult{
+            IsValid: false,
+            Error:   message,
+        }
+    }
+
+    if filepath.Ext(file.Filename) != ".zip" {
+        return invalid("File must be a ZIP archive")
+    }
+
+    src, err := file.Open()
+    if err != nil {
+        return invalid("Failed to read uploaded file")
+    }
+    defer src.Close()
+
+    if err := h.zipService.CheckZipSignature(src); err != nil {
+        if errors.Is(err, services.ErrEmptyZipArchive) {
+            return invalid("ZIP archive is empty")
+        }
+        return invalid("File is not a valid ZIP archive")
+    }
+
+    validation, err := h.zipService.ValidateZipReader(src, file.Size)
+    if err != nil {
+        return invalid("Failed to validate ZIP file")
+    }
+    return validation
+}
+
+// DeleteZip godoc
+// @Summary Delete an uploaded ZIP file
+// @Description Delete an uploaded archive that is no longer needed, e.g. an abandoned upload. Only the uploading user can delete it, and not while it is being extracted.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param file_id path string true "File ID from upload response"
+// @Success 200 {object} utils.APIResponse "ZIP file deleted"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 401 {object} utils.APIError "Unauthorized"
+// @Failure 404 {object} utils.APIError "File not found"
+// @Failure 409 {object} utils.APIError "ZIP file is being extracted"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/zip/{file_id} [delete]
+func (h *ZipHandler) DeleteZip(c *gin.Context) {
+    fileID, ok := middleware.ParamUUID(c, "file_id")
+    if !ok {
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+
+    zipPath, ok := h.findUpload(c, fileID.String())
+    if !ok {
+        return
+    }
+
+    if err := h.zipService.DeleteUpload(userID, fileID, zipPath); err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "ZIP file not found")
+        case errors.Is(err, services.ErrConflict):
+            utils.RespondErrorWithCode(c, http.StatusConflict, utils.ErrCodeExtractionRunning, "ZIP file is being extracted")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to delete ZIP file")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to delete ZIP file")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse("ZIP file deleted successfully"))
+}
+
 // ValidateZip godoc
 // @Summary Validate ZIP file contents
 // @Description Analyze ZIP file and return information about its contents
@@ -111,29 +471,24 @@ func (h *ZipHandler) UploadZip(c *gin.Context) {
 // @Success 200 {object} utils.APIResponse{data=models.ZipValidationResult} "ZIP validation result"
 // @Failure 400 {object} utils.APIError "Bad request"
 // @Failure 404 {object} utils.APIError "File not found"
+// @Failure 429 {object} utils.APIError "Too many validation requests"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/zip/{file_id}/validate [get]
 func (h *ZipHandler) ValidateZip(c *gin.Context) {
     fileID := c.Param("file_id")
     if fileID == "" {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("File ID is required"))
+        utils.RespondError(c, http.StatusBadRequest, "File ID is required")
         return
     }
 
-    // Find ZIP file by ID (simplified - in real app, store file info in database)
-    zipPath := filepath.Join("uploads", "zips", fileID+"_*.zip")
-    
-    // In a real implementation, you would query the database for the file path
-    // For now, we'll assume the file exists at the expected location
-    matches, err := filepath.Glob(zipPath)
-    if err != nil || len(matches) == 0 {
-        c.JSON(http.StatusNotFound, utils.ErrorResponse("ZIP file not found"))
+    zipPath, ok := h.findUpload(c, fileID)
+    if !ok {
         return
     }
 
-    validation, err := h.zipService.ValidateZip(matches[0])
+    validation, err := h.zipService.ValidateZip(zipPath)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to validate ZIP file"))
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to validate ZIP file")
         return
     }
 
@@ -142,22 +497,28 @@ func (h *ZipHandler) ValidateZip(c *gin.Context) {
 
 // ExtractZip godoc
 // @Summary Extract ZIP file
-// @Description Extract ZIP file contents to project directory
+// @Description Extract ZIP file contents to project directory. Extracting into an existing project needs a member who may edit its files.
 // @Tags Files
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param file_id path string true "File ID from upload response"
 // @Param project_id query string false "Project ID (if not provided, generates new UUID)"
+// @Param async query bool false "Extract in the background and return the job"
+// @Param strip_top_level_dir query bool false "Extract the contents of a single top-level folder straight into the project root"
 // @Success 200 {object} utils.APIResponse{data=models.ZipExtractionResult} "ZIP extracted successfully"
+// @Success 202 {object} utils.APIResponse{data=models.ExtractionJob} "Extraction job started"
 // @Failure 400 {object} utils.APIError "Bad request"
-// @Failure 404 {object} utils.APIError "File not found"
+// @Failure 403 {object} utils.APIError "Not allowed to edit the files of the project"
+// @Failure 404 {object} utils.APIError "File or project not found"
+// @Failure 422 {object} utils.APIError "Too many audio files for the project"
+// @Failure 429 {object} utils.APIError "Too many extractions in progress"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/zip/{file_id}/extract [post]
 func (h *ZipHandler) ExtractZip(c *gin.Context) {
     fileID := c.Param("file_id")
     if fileID == "" {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("File ID is required"))
+        utils.RespondError(c, http.StatusBadRequest, "File ID is required")
         return
     }
 
@@ -167,7 +528,7 @@ func (h *ZipHandler) ExtractZip(c *gin.Context) {
     if projectIDStr != "" {
         parsedID, err := uuid.Parse(projectIDStr)
         if err != nil {
-            c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid project ID format"))
+            utils.RespondError(c, http.StatusBadRequest, "Invalid project ID format")
             return
         }
         projectID = parsedID
@@ -175,25 +536,64 @@ func (h *ZipHandler) ExtractZip(c *gin.Context) {
         projectID = uuid.New()
     }
 
-    // Find ZIP file
-    zipPath := filepath.Join("uploads", "zips", fileID+"_*.zip")
-    matches, err := filepath.Glob(zipPath)
-    if err != nil || len(matches) == 0 {
-        c.JSON(http.StatusNotFound, utils.ErrorResponse("ZIP file not found"))
+    zipPath, ok := h.findUpload(c, fileID)
+    if !ok {
+        return
+    }
+
+    // Only members who may edit the files of an existing project can extract into it
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    if err := h.zipService.CheckExtractionTarget(userID, projectID); err != nil {
+        h.respondProjectAccessError(c, err, "Failed to check project access")
+        return
+    }
+
+    // Archives can be extracted into an existing project, whose audio files count too
+    validation, err := h.zipService.ValidateZip(zipPath)
+    if err != nil {
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to validate ZIP file")
+        return
+    }
+    if !h.checkAudioFileLimit(c, projectID, validation.AudioFiles) {
+        return
+    }
+
+    opts := services.ExtractOptions{
+        StripTopLevelDir: c.Query("strip_top_level_dir") == "true",
+        UploadedBy:       userID,
+    }
+
+    if c.Query("async") == "true" {
+        if h.jobs == nil {
+            utils.RespondError(c, http.StatusBadRequest, "Background extraction is not available")
+            return
+        }
+        fileUUID, _ := uuid.Parse(fileID)
+        job := h.jobs.StartExtraction(userID, fileUUID, zipPath, projectID, opts)
+        c.JSON(http.StatusAccepted, utils.SuccessResponse(job))
         return
     }
 
     // Extract ZIP
-    result, err := h.zipService.ExtractZip(matches[0], projectID)
+    result, err := h.zipService.ExtractZipWithOptions(c.Request.Context(), zipPath, projectID, opts)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to extract ZIP file"))
+        if errors.Is(err, services.ErrConflict) {
+            utils.RespondErrorWithCode(c, http.StatusConflict, utils.ErrCodeExtractionRunning, "ZIP file is already being extracted")
+            return
+        }
+        if h.respondExtractionQueueFull(c, err) {
+            return
+        }
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to extract ZIP file")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to extract ZIP file")
         return
     }
 
     if !result.Success {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse(result.Error))
+        utils.RespondError(c, http.StatusInternalServerError, result.Error)
         return
     }
+    h.markExtracted(c, fileID, projectID)
 
     response := struct {
         *models.ZipExtractionResult
@@ -206,40 +606,141 @@ func (h *ZipHandler) ExtractZip(c *gin.Context) {
     c.JSON(http.StatusOK, utils.SuccessResponse(response))
 }
 
+// ExtractEntry godoc
+// @Summary Extract a single ZIP entry
+// @Description Extract one named file from a ZIP archive into the project directory without extracting the rest. Extracting into an existing project needs a member who may edit its files.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param file_id path string true "File ID from upload response"
+// @Param name query string true "Entry name inside the archive"
+// @Param project_id query string false "Project ID (if not provided, generates new UUID)"
+// @Success 200 {object} utils.APIResponse{data=models.ZipFileInfo} "Entry extracted successfully"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not allowed to edit the files of the project"
+// @Failure 404 {object} utils.APIError "File, entry or project not found"
+// @Failure 409 {object} utils.APIError "ZIP file is already being extracted"
+// @Failure 422 {object} utils.APIError "Too many audio files for the project"
+// @Failure 429 {object} utils.APIError "Too many extractions in progress"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/zip/{file_id}/extract-entry [post]
+func (h *ZipHandler) ExtractEntry(c *gin.Context) {
+    fileID := c.Param("file_id")
+    if fileID == "" {
+        utils.RespondError(c, http.StatusBadRequest, "File ID is required")
+        return
+    }
+
+    entryName := c.Query("name")
+    if entryName == "" {
+        utils.RespondError(c, http.StatusBadRequest, "Entry name is required")
+        return
+    }
+
+    // Get project ID or generate new one
+    projectID := uuid.New()
+    if projectIDStr := c.Query("project_id"); projectIDStr != "" {
+        parsedID, err := uuid.Parse(projectIDStr)
+        if err != nil {
+            utils.RespondError(c, http.StatusBadRequest, "Invalid project ID format")
+            return
+        }
+        projectID = parsedID
+    }
+
+    zipPath, ok := h.findUpload(c, fileID)
+    if !ok {
+        return
+    }
+
+    // Only members who may edit the files of an existing project can extract into it
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    if err := h.zipService.CheckExtractionTarget(userID, projectID); err != nil {
+        h.respondProjectAccessError(c, err, "Failed to check project access")
+        return
+    }
+
+    info, err := h.zipService.ExtractEntry(c.Request.Context(), userID, zipPath, entryName, projectID)
+    if err != nil {
+        if h.respondExtractionQueueFull(c, err) || h.respondAudioFileLimit(c, err) {
+            return
+        }
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Entry not found in ZIP file")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not allowed to edit the files of this project")
+        case errors.Is(err, services.ErrInvalid):
+            utils.RespondError(c, http.StatusBadRequest, err.Error())
+        case errors.Is(err, services.ErrConflict):
+            utils.RespondErrorWithCode(c, http.StatusConflict, utils.ErrCodeExtractionRunning, "ZIP file is already being extracted")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to extract entry")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to extract entry")
+        }
+        return
+    }
+
+    response := struct {
+        *models.ZipFileInfo
+        ProjectID string `json:"project_id"`
+    }{
+        ZipFileInfo: info,
+        ProjectID:   projectID.String(),
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(response))
+}
+
 // ListExtractedFiles godoc
 // @Summary List extracted files
-// @Description List all files in an extracted project directory
+// @Description List the files in an extracted project directory, one page at a time. total_files and audio_files count every file matching the filter, not just the returned page. Only members of the project can list them.
 // @Tags Files
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param project_id path string true "Project ID"
 // @Param audio_only query boolean false "Return only audio files"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Maximum number of files per page (default and max 100)"
+// @Param offset query int false "Number of files to skip, used when page is not given"
 // @Success 200 {object} utils.APIResponse{data=[]models.ZipFileInfo} "List of extracted files"
 // @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
 // @Failure 404 {object} utils.APIError "Project not found"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/projects/{project_id}/files [get]
 func (h *ZipHandler) ListExtractedFiles(c *gin.Context) {
-    projectIDStr := c.Param("project_id")
-    projectID, err := uuid.Parse(projectIDStr)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid project ID format"))
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
         return
     }
 
     // Get audio_only parameter
     audioOnly, _ := strconv.ParseBool(c.Query("audio_only"))
 
-    files, err := h.zipService.ListExtractedFiles(projectID)
+    // Projects easily hold more files than other lists have items, hence the larger default page
+    page := apiutils.ParsePagination(c, apiutils.MaxPageSize)
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    files, err := h.zipService.ListExtractedFiles(userID, projectID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to list extracted files"))
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to list extracted files")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to list extracted files")
+        }
         return
     }
 
-    // Filter for audio files only if requested
+    // Filter for audio files only if requested, before paging so totals match the filter
     if audioOnly {
-        var audioFiles []models.ZipFileInfo
+        audioFiles := []models.ZipFileInfo{}
         for _, file := range files {
             if file.IsAudioFile {
                 audioFiles = append(audioFiles, file)
@@ -255,7 +756,7 @@ func (h *ZipHandler) ListExtractedFiles(c *gin.Context) {
         AudioFiles int                   `json:"audio_files"`
     }{
         ProjectID:  projectID.String(),
-        Files:      files,
+        Files:      []models.ZipFileInfo{},
         TotalFiles: len(files),
     }
 
@@ -266,12 +767,118 @@ func (h *ZipHandler) ListExtractedFiles(c *gin.Context) {
         }
     }
 
-    c.JSON(http.StatusOK, utils.SuccessResponse(response))
+    if page.Offset < len(files) {
+        end := page.Offset + page.Limit
+        if end > len(files) {
+            end = len(files)
+        }
+        response.Files = files[page.Offset:end]
+    }
+
+    c.JSON(http.StatusOK, utils.PaginatedResponse(response, page.Limit, page.Offset, len(files)))
+}
+
+// GetExtractedFileInfo godoc
+// @Summary Get one extracted file
+// @Description Return the info of a single extracted file, including its SHA-256 checksum, without listing the whole project. Only members of the project can read it.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID"
+// @Param path query string true "File path relative to the project directory"
+// @Success 200 {object} utils.APIResponse{data=models.ZipFileInfo} "File info"
+// @Failure 400 {object} utils.APIError "Bad request or path outside the project"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "File not found"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/projects/{project_id}/files/info [get]
+func (h *ZipHandler) GetExtractedFileInfo(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
+        return
+    }
+
+    path := c.Query("path")
+    if path == "" {
+        utils.RespondError(c, http.StatusBadRequest, "File path is required")
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    info, err := h.zipService.GetExtractedFileInfo(userID, projectID, path)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrInvalid):
+            utils.RespondError(c, http.StatusBadRequest, "Invalid file path")
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "File not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to read extracted file info")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to read file info")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(info))
+}
+
+// GetFilesMetadata godoc
+// @Summary Get metadata for several files
+// @Description Return size, checksum and audio metadata for a batch of extracted files in one request. Stored metadata is returned when the file has it, otherwise it is read from the file. Non-audio and unknown paths are skipped. Only members of the project can read it.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "Project ID"
+// @Param request body models.FilesMetadataRequest true "Relative file paths (max 100)"
+// @Success 200 {object} utils.APIResponse{data=map[string]models.ExtractedFileMetadata} "Metadata keyed by path"
+// @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not a member of the project"
+// @Failure 404 {object} utils.APIError "Project not found"
+// @Failure 422 {object} utils.APIError "Validation failed"
+// @Failure 500 {object} utils.APIError "Internal server error"
+// @Router /files/projects/{project_id}/files/metadata [post]
+func (h *ZipHandler) GetFilesMetadata(c *gin.Context) {
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
+        return
+    }
+
+    var req models.FilesMetadataRequest
+    if !middleware.BindJSON(c, &req) {
+        return
+    }
+
+    if len(req.Paths) > services.MaxMetadataBatchSize {
+        utils.RespondError(c, http.StatusBadRequest,
+            fmt.Sprintf("At most %d files can be requested at once", services.MaxMetadataBatchSize),
+        )
+        return
+    }
+
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    metadata, err := h.zipService.GetFilesMetadata(userID, projectID, req.Paths)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrNotFound):
+            utils.RespondError(c, http.StatusNotFound, "Project not found")
+        case errors.Is(err, services.ErrForbidden):
+            utils.RespondError(c, http.StatusForbidden, "Not a member of this project")
+        default:
+            logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to read file metadata")
+            utils.RespondError(c, http.StatusInternalServerError, "Failed to read file metadata")
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.SuccessResponse(metadata))
 }
 
 // CreateProjectFromZip godoc
 // @Summary Create project from ZIP
-// @Description Create a new project by extracting and processing a ZIP file
+// @Description Create a new project by extracting a ZIP file. A draft track is created for each audio file, with its duration decoded from the file when possible.
 // @Tags Projects
 // @Accept json
 // @Produce json
@@ -281,58 +888,84 @@ func (h *ZipHandler) ListExtractedFiles(c *gin.Context) {
 // @Success 201 {object} utils.APIResponse{data=models.Project} "Project created successfully"
 // @Failure 400 {object} utils.APIError "Bad request"
 // @Failure 404 {object} utils.APIError "File not found"
+// @Failure 422 {object} utils.APIError "Invalid request fields, invalid archive or too many audio files"
+// @Failure 429 {object} utils.APIError "Too many extractions in progress"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/zip/{file_id}/project [post]
 func (h *ZipHandler) CreateProjectFromZip(c *gin.Context) {
     fileID := c.Param("file_id")
     if fileID == "" {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("File ID is required"))
+        utils.RespondError(c, http.StatusBadRequest, "File ID is required")
         return
     }
 
     // Parse request body
     var req models.ProjectFromZipRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data"))
+    if !middleware.BindJSON(c, &req) {
         return
     }
+    if req.Key != "" {
+        req.Key, _ = models.NormalizeMusicalKey(req.Key)
+    }
 
     // Generate project ID
     projectID := uuid.New()
 
     // Find and extract ZIP file
-    zipPath := filepath.Join("uploads", "zips", fileID+"_*.zip")
-    matches, err := filepath.Glob(zipPath)
-    if err != nil || len(matches) == 0 {
-        c.JSON(http.StatusNotFound, utils.ErrorResponse("ZIP file not found"))
+    zipPath, ok := h.findUpload(c, fileID)
+    if !ok {
+        return
+    }
+
+    // Enforce the per-project audio file limit before extracting anything
+    validation, err := h.zipService.ValidateZip(zipPath)
+    if err != nil {
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to validate ZIP file")
+        return
+    }
+    if !validation.IsValid {
+        utils.RespondErrorWithCode(c, http.StatusUnprocessableEntity, utils.ErrCodeInvalidArchive, validation.Error)
+        return
+    }
+    if !h.checkAudioFileLimit(c, projectID, validation.AudioFiles) {
         return
     }
 
     // Extract ZIP
-    extractResult, err := h.zipService.ExtractZip(matches[0], projectID)
+    extractResult, err := h.zipService.ExtractZipContext(c.Request.Context(), zipPath, projectID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to extract ZIP file"))
+        if h.respondExtractionQueueFull(c, err) {
+            return
+        }
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to extract ZIP file")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to extract ZIP file")
         return
     }
 
     if !extractResult.Success {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse(extractResult.Error))
+        utils.RespondError(c, http.StatusInternalServerError, extractResult.Error)
         return
     }
+    h.markExtracted(c, fileID, projectID)
 
     // Create project model
+    userID, _ := uuid.Parse(c.GetString("user_id"))
     project := models.Project{
         ID:          projectID,
         Name:        req.Name,
         Description: req.Description,
-        // Add other project fields as needed
+        OwnerID:     userID,
+        CreatedBy:   userID,
+        Settings:    req.Settings(),
     }
 
-    // In a real implementation, you would:
-    // 1. Save the project to database
-    // 2. Create file records for extracted files
-    // 3. Process audio metadata
-    // 4. Create initial project structure
+    // Save the project with a track per extracted audio file
+    tracks, err := h.zipService.SaveExtractedProject(c.Request.Context(), &project, extractResult)
+    if err != nil {
+        logger.FromContext(c.Request.Context()).WithError(err).Error("Failed to save project from ZIP file")
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to save project")
+        return
+    }
 
     response := struct {
         models.Project
@@ -340,12 +973,14 @@ func (h *ZipHandler) CreateProjectFromZip(c *gin.Context) {
         AudioFiles     int                   `json:"audio_files"`
         ExtractedPath  string                `json:"extracted_path"`
         Files          []models.ZipFileInfo  `json:"files"`
+        Tracks         []*models.Track       `json:"tracks,omitempty"`
     }{
         Project:        project,
         ExtractedFiles: extractResult.TotalFiles,
         AudioFiles:     len(extractResult.AudioFiles),
         ExtractedPath:  extractResult.ExtractedPath,
         Files:          extractResult.AudioFiles,
+        Tracks:         tracks,
     }
 
     c.JSON(http.StatusCreated, utils.SuccessResponse(response))
@@ -362,26 +997,24 @@ func (h *ZipHandler) CreateProjectFromZip(c *gin.Context) {
 // @Success 200 {object} utils.APIResponse{data=models.ZipValidationResult} "ZIP file information"
 // @Failure 400 {object} utils.APIError "Bad request"
 // @Failure 404 {object} utils.APIError "File not found"
+// @Failure 429 {object} utils.APIError "Too many validation requests"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/zip/{file_id}/info [get]
 func (h *ZipHandler) GetZipInfo(c *gin.Context) {
     fileID := c.Param("file_id")
     if fileID == "" {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("File ID is required"))
+        utils.RespondError(c, http.StatusBadRequest, "File ID is required")
         return
     }
 
-    // Find ZIP file
-    zipPath := filepath.Join("uploads", "zips", fileID+"_*.zip")
-    matches, err := filepath.Glob(zipPath)
-    if err != nil || len(matches) == 0 {
-        c.JSON(http.StatusNotFound, utils.ErrorResponse("ZIP file not found"))
+    zipPath, ok := h.findUpload(c, fileID)
+    if !ok {
         return
     }
 
-    info, err := h.zipService.GetZipInfo(matches[0])
+    info, err := h.zipService.GetZipInfo(zipPath)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to get ZIP information"))
+        utils.RespondError(c, http.StatusInternalServerError, "Failed to get ZIP information")
         return
     }
 
@@ -390,7 +1023,7 @@ func (h *ZipHandler) GetZipInfo(c *gin.Context) {
 
 // CleanupProject godoc
 // @Summary Cleanup project files
-// @Description Remove all extracted files for a project
+// @Description Remove all extracted files for a project. Only members who may edit its files can remove them.
 // @Tags Files
 // @Accept json
 // @Produce json
@@ -398,20 +1031,54 @@ func (h *ZipHandler) GetZipInfo(c *gin.Context) {
 // @Param project_id path string true "Project ID"
 // @Success 200 {object} utils.APIResponse{data=string} "Files cleaned up successfully"
 // @Failure 400 {object} utils.APIError "Bad request"
+// @Failure 403 {object} utils.APIError "Not allowed to edit the files of the project"
+// @Failure 404 {object} utils.APIError "Project not found"
 // @Failure 500 {object} utils.APIError "Internal server error"
 // @Router /files/projects/{project_id}/cleanup [delete]
 func (h *ZipHandler) CleanupProject(c *gin.Context) {
-    projectIDStr := c.Param("project_id")
-    projectID, err := uuid.Parse(projectIDStr)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid project ID format"))
+    projectID, ok := middleware.ParamUUID(c, "project_id")
+    if !ok {
         return
     }
 
-    if err := h.zipService.CleanupExtractedFiles(projectID); err != nil {
-        c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to cleanup project files"))
+    userID, _ := uuid.Parse(c.GetString("user_id"))
+    if err := h.zipService.CleanupExtractedFiles(userID, projectID); err != nil {
+        h.respondProjectAccessError(c, err, "Failed to cleanup project files")
         return
     }
 
     c.JSON(http.StatusOK, utils.SuccessResponse("Project files cleaned up successfully"))
-}
\ No newline at end of file
+}
+
+// saveUploadedFile writes a multipart file to disk, refusing to write more than maxSize bytes
+// regardless of the size the part declared. A partially written file is removed.
+func saveUploadedFile(file *multipart.FileHeader, dst string, maxSize int64) error {
+    src, err := file.Open()
+    if err != nil {
+        return err
+    }
+    defer src.Close()
+
+    if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+        return err
+    }
+
+    out, err := os.Create(dst)
+    if err != nil {
+        return err
+    }
+
+    written, err := io.Copy(out, io.LimitReader(src, maxSize+1))
+    if closeErr := out.Close(); err == nil {
+        err = closeErr
+    }
+    if err == nil && written > maxSize {
+        err = errUploadTooLarge
+    }
+    if err != nil {
+        os.Remove(dst)
+        return err
+    }
+
+    return nil
+}
@@ -1,14 +1,20 @@
 package middleware
 
 import (
+	"os"
 	"strings"
 
+	"collabhub-music-backend/pkg/logger"
 	"collabhub-music-backend/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware provides JWT token authentication for protected routes
+// AuthMiddleware provides mock token authentication for tests and local development.
+// Real deployments must use the Keycloak-backed RequireAuth of internal/api/middleware;
+// creating this middleware in production is a fatal error.
 func AuthMiddleware() gin.HandlerFunc {
+	guardMockAuth()
+
 	return func(c *gin.Context) {
 		// Extract Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -47,6 +53,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Set("user_id", "mock-user-id")
 			c.Set("username", "mock-user")
 			c.Set("email", "user@example.com")
+			c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), "mock-user-id"))
 			c.Next()
 		} else {
 			utils.UnauthorizedResponse(c, "Invalid or expired token")
@@ -57,12 +64,32 @@ func AuthMiddleware() gin.HandlerFunc {
 
 // isValidToken is a mock function - replace with real Keycloak token validation
 func isValidToken(token string) bool {
+	// Never accept mock tokens in production, even if the startup guard was bypassed
+	if isProduction() {
+		return false
+	}
 	// Mock validation - in production, this should validate with Keycloak
 	return len(token) > 10 // Very basic check
 }
 
-// OptionalAuthMiddleware provides optional authentication for routes that can work with or without auth
+// isProduction reports whether the server runs in production,
+// either through GIN_MODE=release or GO_ENV=production
+func isProduction() bool {
+	return gin.Mode() == gin.ReleaseMode || os.Getenv("GO_ENV") == "production"
+}
+
+// guardMockAuth stops the server when the mock middleware is created in production
+func guardMockAuth() {
+	if isProduction() {
+		logger.Fatal("Mock authentication middleware must not be used in production, use the Keycloak RequireAuth middleware")
+	}
+}
+
+// OptionalAuthMiddleware provides optional mock authentication for routes that can work with or without auth.
+// Like AuthMiddleware, it is for tests and local development only.
 func OptionalAuthMiddleware() gin.HandlerFunc {
+	guardMockAuth()
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 
@@ -75,6 +102,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 				c.Set("username", "mock-user")
 				c.Set("email", "user@example.com")
 				c.Set("authenticated", true)
+				c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), "mock-user-id"))
 			}
 		} else {
 			// No auth provided, continue as anonymous user
@@ -84,3 +112,9 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// GetCurrentUserID returns the ID of the authenticated user, as set by the auth middleware
+func GetCurrentUserID(c *gin.Context) (string, bool) {
+	userID := c.GetString("user_id")
+	return userID, userID != ""
+}
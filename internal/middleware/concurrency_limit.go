@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"collabhub-music-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimiter allows at most limit requests in flight per key at once. Like
+// RateLimiter it keeps its counters in memory, so limits are per server instance.
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight map[string]int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing limit requests in flight per key;
+// 0 or less allows any number
+func NewConcurrencyLimiter(limit int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		limit:    limit,
+		inFlight: make(map[string]int),
+	}
+}
+
+// Acquire takes a slot for key and reports whether one was free. Every successful
+// Acquire must be matched by a Release.
+func (l *ConcurrencyLimiter) Acquire(key string) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] >= l.limit {
+		return false
+	}
+	l.inFlight[key]++
+	return true
+}
+
+// Release gives back a slot taken by Acquire
+func (l *ConcurrencyLimiter) Release(key string) {
+	if l.limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop idle keys so the map doesn't grow with every user seen
+	if l.inFlight[key] <= 1 {
+		delete(l.inFlight, key)
+		return
+	}
+	l.inFlight[key]--
+}
+
+// Middleware limits the requests in flight per authenticated user, falling back to the
+// client IP for anonymous requests, and answers 429 once the limit is reached. The slot
+// is released when the request finishes, whether it succeeded, failed or panicked.
+func (l *ConcurrencyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetString("user_id")
+		if key == "" {
+			key = c.ClientIP()
+		}
+
+		if !l.Acquire(key) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests,
+				utils.NewError(http.StatusTooManyRequests, "Too many uploads in progress, wait for one to finish"))
+			return
+		}
+		defer l.Release(key)
+
+		c.Next()
+	}
+}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -24,3 +27,45 @@ func Logger() gin.HandlerFunc {
 		return ""
 	})
 }
+
+// maxLoggedBodySize caps how much of a request body is read for logging
+const maxLoggedBodySize = 64 << 10 // 64KB
+
+// RequestLogger logs each request with its headers and JSON body, masking the given
+// sensitive fields first. An empty field list uses DefaultRedactedFields.
+func RequestLogger(redactFields []string) gin.HandlerFunc {
+	if len(redactFields) == 0 {
+		redactFields = DefaultRedactedFields
+	}
+	redactor := NewRedactor(redactFields)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		var body []byte
+		if c.Request.Body != nil && strings.HasPrefix(c.ContentType(), "application/json") {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBodySize+1))
+			// Put the bytes back so handlers still see the full body
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		c.Next()
+
+		fields := logrus.Fields{
+			"client_ip": c.ClientIP(),
+			"method":    c.Request.Method,
+			"path":      c.Request.URL.Path,
+			"status":    c.Writer.Status(),
+			"latency":   time.Since(start),
+			"headers":   redactor.RedactHeaders(c.Request.Header),
+		}
+		switch {
+		case len(body) > maxLoggedBodySize:
+			fields["body"] = "[body too large to log]"
+		case len(body) > 0:
+			fields["body"] = redactor.RedactJSON(body)
+		}
+
+		logrus.WithFields(fields).Info("API Request")
+	}
+}
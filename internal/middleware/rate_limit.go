@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"collabhub-music-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// rateWindow counts the requests of one client in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter allows at most limit requests per key in each fixed window.
+// It keeps its counters in memory, so limits are per server instance.
+type RateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*rateWindow
+	now     func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing limit requests per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+}
+
+// Allow records a request for key and reports whether it is within the limit.
+// When it is not, it also returns how long until the key's window resets.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.evictExpired(now)
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// evictExpired drops the windows that have ended so the map doesn't grow with every client seen
+func (l *RateLimiter) evictExpired(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// Middleware limits requests per authenticated user, falling back to the client IP for
+// anonymous requests, answering 429 with a Retry-After header
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetString("user_id")
+		if key == "" {
+			key = c.ClientIP()
+		}
+
+		allowed, retryAfter := l.Allow(key)
+		if !allowed {
+			SetRetryAfter(c, retryAfter)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, utils.NewError(http.StatusTooManyRequests, "Too many requests"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetRetryAfter sets the Retry-After header, rounding the wait up to whole seconds
+func SetRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
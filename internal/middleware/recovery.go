@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"collabhub-music-backend/pkg/logger"
+	"collabhub-music-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Recovery turns a panic in a later handler into a 500 with the standard JSON error body,
+// where gin.Recovery would write a plain text response clients can't parse. The panic and
+// its stack are logged with the request ID, so RequestID should run first. A response
+// that was already partly written is left as it is.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			logger.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+				"panic": fmt.Sprint(recovered),
+				"stack": string(debug.Stack()),
+			}).Error("Recovered from panic")
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError,
+				utils.NewError(http.StatusInternalServerError, "Internal server error"))
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// redactedValue replaces the value of every sensitive field in logs
+const redactedValue = "***"
+
+// DefaultRedactedFields are the field and header names masked in logged requests
+var DefaultRedactedFields = []string{"password", "client_secret", "authorization", "token"}
+
+// Redactor masks sensitive fields in request bodies and headers before they are logged.
+// A field matches when its name equals a configured name or ends with "_<name>"
+// (so "token" also covers "access_token"), ignoring case.
+type Redactor struct {
+	fields []string
+}
+
+// NewRedactor creates a redactor for the given field names
+func NewRedactor(fields []string) *Redactor {
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field != "" {
+			normalized = append(normalized, field)
+		}
+	}
+	return &Redactor{fields: normalized}
+}
+
+// isSensitive reports whether a field or header name must be masked
+func (r *Redactor) isSensitive(name string) bool {
+	name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+	for _, field := range r.fields {
+		if name == field || strings.HasSuffix(name, "_"+field) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns the headers as a flat map with sensitive values masked
+func (r *Redactor) RedactHeaders(headers http.Header) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if r.isSensitive(name) {
+			redacted[name] = redactedValue
+			continue
+		}
+		redacted[name] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// RedactJSON masks sensitive fields at any depth of a JSON document.
+// Bodies that are not valid JSON are not logged at all, since they can't be inspected.
+func (r *Redactor) RedactJSON(body []byte) interface{} {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "[unparseable body omitted]"
+	}
+	return r.redactValue(data)
+}
+
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.isSensitive(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = r.redactValue(child)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redactValue(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
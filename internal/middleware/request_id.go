@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"collabhub-music-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header carrying the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID assigns every request an ID, reusing the one sent by the client if any.
+// The ID is echoed in the response and stored, with the matched route, in the
+// request context for logger.FromContext.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.New().String()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := logger.WithRequestID(c.Request.Context(), requestID)
+		ctx = logger.WithRoute(ctx, c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"collabhub-music-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// uuidParamKeyPrefix namespaces parsed path parameters in the gin context
+const uuidParamKeyPrefix = "uuid_param."
+
+// UUIDParam validates that the named path parameter is a UUID before the handler
+// runs. The parsed value is stored for ParamUUID and malformed values are rejected
+// with the standard 400 error body.
+func UUIDParam(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param(name))
+		if err != nil {
+			abortInvalidUUID(c, name)
+			return
+		}
+
+		c.Set(uuidParamKeyPrefix+name, id)
+		c.Next()
+	}
+}
+
+// ParamUUID returns the named path parameter as parsed by UUIDParam. Routes
+// mounted without the middleware are parsed here instead, so the response for a
+// malformed id is the same either way. It reports false after responding with 400.
+func ParamUUID(c *gin.Context, name string) (uuid.UUID, bool) {
+	if value, exists := c.Get(uuidParamKeyPrefix + name); exists {
+		if id, ok := value.(uuid.UUID); ok {
+			return id, true
+		}
+	}
+
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		abortInvalidUUID(c, name)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func abortInvalidUUID(c *gin.Context, name string) {
+	c.AbortWithStatusJSON(http.StatusBadRequest,
+		utils.NewError(http.StatusBadRequest, fmt.Sprintf("Invalid %s: must be a UUID", name)))
+}
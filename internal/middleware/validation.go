@@ -1,7 +1,17 @@
 package middleware
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"collabhub-music-backend/internal/models"
+	"collabhub-music-backend/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -9,6 +19,33 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
+	validate.RegisterValidation("musical_key", isMusicalKey)
+
+	// Gin validates bound requests with its own validator instance
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(jsonFieldName)
+		engine.RegisterValidation("musical_key", isMusicalKey)
+	}
+}
+
+// isMusicalKey implements the musical_key tag, accepting keys such as "C", "F#m" or "bbm"
+func isMusicalKey(fl validator.FieldLevel) bool {
+	_, ok := models.NormalizeMusicalKey(fl.Field().String())
+	return ok
+}
+
+// jsonFieldName names struct fields in validation errors after their json tag, so clients
+// see the keys they sent rather than Go field names
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
 }
 
 // ValidationError represents a field validation error
@@ -23,12 +60,13 @@ func ValidateJSON(v interface{}) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if err := c.ShouldBindJSON(v); err != nil {
 			var errors []ValidationError
+			lang := preferredLanguage(c.GetHeader("Accept-Language"))
 
 			if validationErrors, ok := err.(validator.ValidationErrors); ok {
 				for _, validationError := range validationErrors {
 					errors = append(errors, ValidationError{
 						Field:   validationError.Field(),
-						Message: getValidationMessage(validationError),
+						Message: getValidationMessage(validationError, lang),
 						Value:   validationError.Value(),
 					})
 				}
@@ -49,6 +87,63 @@ func ValidateJSON(v interface{}) gin.HandlerFunc {
 	}
 }
 
+// BindingErrors translates an error from binding a JSON body into field-level errors named
+// by JSON key, with messages in the language of the request. Failed validations and values
+// of the wrong type are translated; for malformed bodies and other errors it returns nil.
+// Submitted values are left out so that secrets aren't echoed back.
+func BindingErrors(c *gin.Context, err error) []ValidationError {
+	lang := preferredLanguage(c.GetHeader("Accept-Language"))
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]ValidationError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fieldErrors = append(fieldErrors, ValidationError{
+				Field:   fieldPath(fe.Namespace()),
+				Message: getValidationMessage(fe, lang),
+			})
+		}
+		return fieldErrors
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		message, ok := validationMessages[lang]["default"]
+		if !ok {
+			message = validationMessages[defaultLanguage]["default"]
+		}
+		return []ValidationError{{Field: typeError.Field, Message: message}}
+	}
+
+	return nil
+}
+
+// fieldPath drops the struct name a validator namespace starts with, turning
+// "CreateProjectRequest.settings.bpm" into "settings.bpm"
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// BindJSON binds the JSON request body into obj. Invalid fields get a 422 listing them by
+// JSON key and malformed bodies a 400, both in the standard error format; it reports false
+// once it has responded.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	if fieldErrors := BindingErrors(c, err); fieldErrors != nil {
+		utils.RespondValidationError(c, "Validation failed", fieldErrors)
+	} else {
+		utils.RespondError(c, http.StatusBadRequest, "Request body is not valid JSON")
+	}
+	return false
+}
+
 // ValidateQuery validates query parameters
 func ValidateQuery(c *gin.Context, v interface{}) error {
 	if err := c.ShouldBindQuery(v); err != nil {
@@ -75,28 +170,96 @@ func ValidateParams(c *gin.Context, v interface{}) error {
 	return nil
 }
 
-// getValidationMessage returns user-friendly validation messages
-func getValidationMessage(fe validator.FieldError) string {
-	switch fe.Tag() {
-	case "required":
-		return "This field is required"
-	case "email":
-		return "Must be a valid email address"
-	case "min":
-		return "Value is too short (minimum " + fe.Param() + " characters)"
-	case "max":
-		return "Value is too long (maximum " + fe.Param() + " characters)"
-	case "gte":
-		return "Value must be greater than or equal to " + fe.Param()
-	case "lte":
-		return "Value must be less than or equal to " + fe.Param()
-	case "uuid4":
-		return "Must be a valid UUID"
-	case "oneof":
-		return "Value must be one of: " + fe.Param()
-	default:
-		return "Invalid value"
+// defaultLanguage is used when the client accepts none of the catalog languages
+const defaultLanguage = "en"
+
+// validationMessages is the message catalog, keyed by language then validation tag.
+// "{param}" in a message is replaced by the tag parameter and "default" is used for unknown tags.
+var validationMessages = map[string]map[string]string{
+	"en": {
+		"required":    "This field is required",
+		"email":       "Must be a valid email address",
+		"min":         "Value is too short (minimum {param} characters)",
+		"max":         "Value is too long (maximum {param} characters)",
+		"gte":         "Value must be greater than or equal to {param}",
+		"lte":         "Value must be less than or equal to {param}",
+		"uuid4":       "Must be a valid UUID",
+		"oneof":       "Value must be one of: {param}",
+		"musical_key": "Must be a musical key such as C, F#m or Bbm",
+		"default":     "Invalid value",
+	},
+	"fr": {
+		"required":    "Ce champ est obligatoire",
+		"email":       "Doit être une adresse e-mail valide",
+		"min":         "Valeur trop courte (minimum {param} caractères)",
+		"max":         "Valeur trop longue (maximum {param} caractères)",
+		"gte":         "La valeur doit être supérieure ou égale à {param}",
+		"lte":         "La valeur doit être inférieure ou égale à {param}",
+		"uuid4":       "Doit être un UUID valide",
+		"oneof":       "La valeur doit être l'une des suivantes : {param}",
+		"musical_key": "Doit être une tonalité, par exemple C, F#m ou Bbm",
+		"default":     "Valeur invalide",
+	},
+}
+
+// RegisterValidationMessage adds or replaces the message for a validation tag in a language.
+// It is meant to be called during initialization, before requests are served.
+func RegisterValidationMessage(lang, tag, message string) {
+	lang = strings.ToLower(lang)
+	if validationMessages[lang] == nil {
+		validationMessages[lang] = map[string]string{}
+	}
+	validationMessages[lang][tag] = message
+}
+
+// preferredLanguage returns the catalog language that best matches an Accept-Language header
+func preferredLanguage(acceptLanguage string) string {
+	best := defaultLanguage
+	bestQuality := -1.0
+
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ";")
+		tag := strings.ToLower(strings.TrimSpace(parts[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		// Match on the primary subtag so "fr-CA" selects "fr"
+		lang := strings.SplitN(tag, "-", 2)[0]
+		if _, ok := validationMessages[lang]; ok && quality > bestQuality {
+			best = lang
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// getValidationMessage returns a user-friendly validation message in the given language,
+// falling back to English for languages or tags missing from the catalog
+func getValidationMessage(fe validator.FieldError, lang string) string {
+	message, ok := validationMessages[lang][fe.Tag()]
+	if !ok {
+		message, ok = validationMessages[defaultLanguage][fe.Tag()]
 	}
+	if !ok {
+		message, ok = validationMessages[lang]["default"]
+	}
+	if !ok {
+		message = validationMessages[defaultLanguage]["default"]
+	}
+
+	return strings.ReplaceAll(message, "{param}", fe.Param())
 }
 
 // ValidationMiddleware returns a middleware that validates request data
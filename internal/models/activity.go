@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityType identifies the kind of event shown in a project activity feed
+type ActivityType string
+
+const (
+	ActivityFileAdded          ActivityType = "file_added"
+	ActivityVersionCreated     ActivityType = "version_created"
+	ActivityCollaboratorJoined ActivityType = "collaborator_joined"
+	ActivityBranchCreated      ActivityType = "branch_created"
+)
+
+// ActivityEvent represents a single entry in a project activity feed
+type ActivityEvent struct {
+	Type          ActivityType `json:"type"`
+	TargetID      uuid.UUID    `json:"target_id"`
+	TargetName    string       `json:"target_name,omitempty"`
+	ActorID       uuid.UUID    `json:"actor_id"`
+	ActorUsername string       `json:"actor_username,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
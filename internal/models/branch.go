@@ -27,6 +27,41 @@ type Branch struct {
     Files     []File  `json:"files,omitempty" gorm:"foreignKey:BranchID"`
 }
 
+// BranchSummary is a branch as listed in a project browser, with the number of files on it
+type BranchSummary struct {
+    Branch
+    FileCount     int64     `json:"file_count"`
+    LastUpdatedAt time.Time `json:"last_updated_at"` // latest change to the branch or one of its files
+}
+
+// BranchFileStats counts the files on one branch
+type BranchFileStats struct {
+    BranchID      uuid.UUID
+    FileCount     int64
+    LastUpdatedAt time.Time // most recent file update
+}
+
+// MergeBranchRequest represents a request to merge a branch into another
+type MergeBranchRequest struct {
+    TargetBranchID uuid.UUID `json:"target_branch_id" binding:"required"`
+}
+
+// RenameBranchRequest represents a request to rename a branch
+type RenameBranchRequest struct {
+    Name string `json:"name" binding:"required,max=100"`
+}
+
+// MergeResult summarizes a metadata-level merge of one branch into another.
+// Every list holds file paths.
+type MergeResult struct {
+    SourceBranchID uuid.UUID `json:"source_branch_id"`
+    TargetBranchID uuid.UUID `json:"target_branch_id"`
+    Added          []string  `json:"added"`     // only on the source, copied to the target
+    Updated        []string  `json:"updated"`   // newer on the source, target updated
+    Unchanged      []string  `json:"unchanged"` // identical, or only the target changed
+    Conflicts      []string  `json:"conflicts"` // changed on both branches, left untouched
+}
+
 // BeforeCreate hook to set ID
 func (b *Branch) BeforeCreate(tx *gorm.DB) error {
     if b.ID == uuid.Nil {
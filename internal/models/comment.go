@@ -2,19 +2,43 @@ package models
 
 import (
     "time"
+
     "github.com/google/uuid"
+    "gorm.io/gorm"
 )
 
+// Comment is a note left on a project, optionally about one of its tracks or files.
+// Replies point at the comment they answer with ParentID.
 type Comment struct {
-    ID              uuid.UUID  `json:"id" db:"id"`
-    ProjectID       *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
-    TrackID         *uuid.UUID `json:"track_id,omitempty" db:"track_id"`
-    FileID          *uuid.UUID `json:"file_id,omitempty" db:"file_id"`
-    ParentCommentID *uuid.UUID `json:"parent_comment_id,omitempty" db:"parent_comment_id"`
-    UserID          uuid.UUID  `json:"user_id" db:"user_id"`
-    Content         string     `json:"content" db:"content"`
-    Timestamp       *int       `json:"timestamp,omitempty" db:"timestamp"` // for audio comments in seconds
-    CreatedAt       time.Time  `json:"created_at" db:"created_at"`
-    UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
-    DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
-}
\ No newline at end of file
+    ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    ProjectID uuid.UUID      `json:"project_id" gorm:"type:uuid;not null;index"`
+    TrackID   *uuid.UUID     `json:"track_id,omitempty" gorm:"type:uuid"`
+    FileID    *uuid.UUID     `json:"file_id,omitempty" gorm:"type:uuid"`
+    ParentID  *uuid.UUID     `json:"parent_id,omitempty" gorm:"type:uuid;index"`
+    AuthorID  uuid.UUID      `json:"author_id" gorm:"type:uuid;not null"`
+    Body      string         `json:"body" gorm:"type:text;not null"`
+    Timestamp *int           `json:"timestamp,omitempty"` // position in the track or file, in seconds
+    CreatedAt time.Time      `json:"created_at"`
+    UpdatedAt time.Time      `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+    // Relationships
+    Author User `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+}
+
+// CreateCommentRequest is the body of a new comment or reply
+type CreateCommentRequest struct {
+    Body      string     `json:"body" binding:"required,max=5000"`
+    TrackID   *uuid.UUID `json:"track_id,omitempty"`
+    FileID    *uuid.UUID `json:"file_id,omitempty"`
+    ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+    Timestamp *int       `json:"timestamp,omitempty" binding:"omitempty,min=0"`
+}
+
+// CommentPage is a page of a project's comments, oldest first
+type CommentPage struct {
+    Comments []*Comment `json:"comments"`
+    Total    int64      `json:"total"`
+    Limit    int        `json:"limit"`
+    Offset   int        `json:"offset"`
+}
@@ -51,7 +51,27 @@ type FileVersion struct {
     Creator User `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
 }
 
-// AudioMetadata represents metadata for audio files
+// FieldChange describes a field whose value differs between two versions
+type FieldChange struct {
+    Field string      `json:"field"`
+    From  interface{} `json:"from"`
+    To    interface{} `json:"to"`
+}
+
+// FileVersionDiff describes what changed between two versions of a file
+type FileVersionDiff struct {
+    FileID        uuid.UUID     `json:"file_id"`
+    FromVersion   int           `json:"from_version"`
+    ToVersion     int           `json:"to_version"`
+    SizeFrom      int64         `json:"size_from"`
+    SizeTo        int64         `json:"size_to"`
+    SizeDelta     int64         `json:"size_delta"`
+    ChecksumEqual bool          `json:"checksum_equal"`
+    Changes       []FieldChange `json:"changes"`
+}
+
+// AudioMetadata represents metadata for audio files. It is the source of truth for what
+// was read from the file; the BPM, key, genre and duration of the file's Track mirror it.
 type AudioMetadata struct {
     ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
     FileID   uuid.UUID `json:"file_id" gorm:"type:uuid;not null;uniqueIndex"`
@@ -59,6 +79,8 @@ type AudioMetadata struct {
     Artist   string    `json:"artist"`
     Album    string    `json:"album"`
     Genre    string    `json:"genre"`
+    BPM      int       `json:"bpm"`
+    Key      string    `json:"key"`
     Year     int       `json:"year"`
     Track    int       `json:"track"`
     Duration float64   `json:"duration"` // in seconds
@@ -68,8 +90,63 @@ type AudioMetadata struct {
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 
-    // Relationships
-    File File `json:"file,omitempty" gorm:"foreignKey:FileID"`
+    // Relationships; metadata is always read through its file, so the file isn't serialized
+    File File `json:"-" gorm:"foreignKey:FileID"`
+}
+
+// FileMetadata is the audio metadata of a file. HasMetadata is false, and Metadata nil, for
+// files that haven't been analyzed, including files that aren't audio.
+type FileMetadata struct {
+    FileID      uuid.UUID      `json:"file_id"`
+    HasMetadata bool           `json:"has_metadata"`
+    Metadata    *AudioMetadata `json:"metadata"`
+}
+
+// AudioMetadataPatch holds the audio metadata fields to set on several files at once.
+// Empty and zero fields are left unchanged. BPM, key and genre are mirrored onto tracks
+// and are read from the audio instead.
+type AudioMetadataPatch struct {
+    Title  string `json:"title" binding:"max=255"`
+    Artist string `json:"artist" binding:"max=255"`
+    Album  string `json:"album" binding:"max=255"`
+    Year   int    `json:"year"`
+    Track  int    `json:"track"`
+}
+
+// IsEmpty reports whether the patch sets no field
+func (p AudioMetadataPatch) IsEmpty() bool {
+    return p == AudioMetadataPatch{}
+}
+
+// Apply sets the patch's non-empty fields on metadata
+func (p AudioMetadataPatch) Apply(metadata *AudioMetadata) {
+    if p.Title != "" {
+        metadata.Title = p.Title
+    }
+    if p.Artist != "" {
+        metadata.Artist = p.Artist
+    }
+    if p.Album != "" {
+        metadata.Album = p.Album
+    }
+    if p.Year != 0 {
+        metadata.Year = p.Year
+    }
+    if p.Track != 0 {
+        metadata.Track = p.Track
+    }
+}
+
+// BulkMetadataRequest represents a request to tag several files of a project at once
+type BulkMetadataRequest struct {
+    Paths    []string           `json:"paths" binding:"required,min=1"`
+    Metadata AudioMetadataPatch `json:"metadata"`
+}
+
+// BulkMetadataResult summarizes a bulk metadata update
+type BulkMetadataResult struct {
+    Updated int      `json:"updated"`
+    Skipped []string `json:"skipped"` // paths that aren't audio files of the project's default branch
 }
 
 // BeforeCreate hooks
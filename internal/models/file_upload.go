@@ -1,6 +1,7 @@
 package models
 
 import (
+    "strings"
     "time"
 
     "github.com/google/uuid"
@@ -21,6 +22,16 @@ type FileUpload struct {
     UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// UploadSummary is the part of a FileUpload shown to the user who uploaded it
+type UploadSummary struct {
+    ID           uuid.UUID  `json:"id"`
+    OriginalName string     `json:"original_name"`
+    Size         int64      `json:"size"`
+    IsExtracted  bool       `json:"is_extracted"`
+    ProjectID    *uuid.UUID `json:"project_id,omitempty"`
+    CreatedAt    time.Time  `json:"created_at"`
+}
+
 // ZipValidationResult represents ZIP file validation result
 type ZipValidationResult struct {
     IsValid          bool     `json:"is_valid"`
@@ -31,6 +42,38 @@ type ZipValidationResult struct {
     TotalSize        int64    `json:"total_size"`
     SupportedFiles   []string `json:"supported_files"`
     UnsupportedFiles []string `json:"unsupported_files"`
+    HiddenFiles      int      `json:"hidden_files"` // hidden entries left out of the counts above
+    CorruptedFiles   []string `json:"corrupted_files,omitempty"` // entries failing their CRC32, when checked
+
+    // UnsupportedFileDetails describes each entry of UnsupportedFiles, in the same order
+    UnsupportedFileDetails []UnsupportedFileInfo `json:"unsupported_file_details"`
+}
+
+// UnsupportedFileInfo describes an archive entry that isn't a supported audio file
+type UnsupportedFileInfo struct {
+    Name        string `json:"name"`
+    Size        int64  `json:"size"`         // uncompressed
+    ContentType string `json:"content_type"` // sniffed from the first bytes, else from the extension
+}
+
+// ZipBatchValidationItem is the validation result of one archive in a batch
+type ZipBatchValidationItem struct {
+    Filename string               `json:"filename"`
+    Result   *ZipValidationResult `json:"result"`
+}
+
+// ZipBatchValidationSummary sums up the validation of a batch of archives
+type ZipBatchValidationSummary struct {
+    TotalArchives   int `json:"total_archives"`
+    ValidArchives   int `json:"valid_archives"`
+    InvalidArchives int `json:"invalid_archives"`
+    AudioFiles      int `json:"audio_files"` // across valid archives
+}
+
+// ZipBatchValidationResult represents the validation of several ZIP files at once
+type ZipBatchValidationResult struct {
+    Archives []ZipBatchValidationItem  `json:"archives"`
+    Summary  ZipBatchValidationSummary `json:"summary"`
 }
 
 // ZipFileInfo represents information about a file in ZIP
@@ -42,24 +85,138 @@ type ZipFileInfo struct {
     ContentType  string    `json:"content_type"`
     IsAudioFile  bool      `json:"is_audio_file"`
     ModTime      time.Time `json:"mod_time"`
+    Checksum     string    `json:"checksum,omitempty"` // hex SHA-256 of the content, when known
 }
 
 // ZipExtractionResult represents ZIP extraction result
 type ZipExtractionResult struct {
-    Success        bool          `json:"success"`
-    ExtractedPath  string        `json:"extracted_path"`
-    ExtractedFiles []ZipFileInfo `json:"extracted_files"`
-    AudioFiles     []ZipFileInfo `json:"audio_files"`
-    TotalFiles     int           `json:"total_files"`
-    TotalSize      int64         `json:"total_size"`
-    Error          string        `json:"error,omitempty"`
+    Success        bool               `json:"success"`
+    ExtractedPath  string             `json:"extracted_path"`
+    ExtractedFiles []ZipFileInfo      `json:"extracted_files"`
+    AudioFiles     []ZipFileInfo      `json:"audio_files"`
+    TotalFiles     int                `json:"total_files"`
+    TotalSize      int64              `json:"total_size"`
+    SkippedFiles   []SkippedZipEntry  `json:"skipped_files"`
+    StrippedPrefix string             `json:"stripped_prefix,omitempty"` // top-level directory removed from every path
+    Timings        *ExtractionTimings `json:"timings,omitempty"`
+    Error          string             `json:"error,omitempty"`
+}
+
+// ExtractionTimings breaks down where an extraction spent its time. Durations are in
+// nanoseconds; Total also covers waiting for a free extraction slot.
+type ExtractionTimings struct {
+    Queue    time.Duration `json:"queue_ns"`
+    Open     time.Duration `json:"open_ns"`
+    Validate time.Duration `json:"validate_ns"`
+    Write    time.Duration `json:"write_ns"`
+    Total    time.Duration `json:"total_ns"`
+}
+
+// SkippedZipEntry is an archive entry that was not extracted, with the reason why
+type SkippedZipEntry struct {
+    Path   string `json:"path"`
+    Reason string `json:"reason"`
+}
+
+// AudioInfo holds the technical properties read from an audio file header, and the
+// musical properties read from its ID3 tag when it has one
+type AudioInfo struct {
+    Title      string  `json:"title"`
+    Duration   float64 `json:"duration"`    // in seconds
+    BitRate    int     `json:"bit_rate"`    // in kbps
+    SampleRate int     `json:"sample_rate"` // in Hz
+    BitDepth   int     `json:"bit_depth,omitempty"`
+    Channels   int     `json:"channels"`
+    BPM        int     `json:"bpm,omitempty"`
+    Key        string  `json:"key,omitempty"`
+    Genre      string  `json:"genre,omitempty"`
+}
+
+// ExtractedFileMetadata describes an extracted audio file. AudioMetadata has no ID or
+// FileID when the file has no stored metadata and was read from disk instead.
+type ExtractedFileMetadata struct {
+    Path          string         `json:"path"`
+    Size          int64          `json:"size"`
+    Checksum      string         `json:"checksum"`
+    ContentType   string         `json:"content_type"`
+    AudioMetadata *AudioMetadata `json:"audio_metadata"`
+}
+
+// FilesMetadataRequest represents a request for the metadata of several extracted files
+type FilesMetadataRequest struct {
+    Paths []string `json:"paths" binding:"required,min=1"`
+}
+
+// ReprocessResult summarizes a metadata reprocessing run over a project's extracted files
+type ReprocessResult struct {
+    ProjectID uuid.UUID `json:"project_id"`
+    Scanned   int       `json:"scanned"`
+    Updated   []string  `json:"updated"`
+    Unchanged int       `json:"unchanged"`
+    Created   []string  `json:"created"` // on disk without a File row until now
+}
+
+// ResyncResult summarizes how the File rows of a project's default branch were reconciled
+// with its extracted files
+type ResyncResult struct {
+    ProjectID uuid.UUID `json:"project_id"`
+    BranchID  uuid.UUID `json:"branch_id"`
+    Created   []string  `json:"created"` // on disk but had no File row
+    Deleted   []string  `json:"deleted"` // had a File row but are gone from disk
+    Unchanged int       `json:"unchanged"`
+}
+
+// UploadCapabilities describes the formats and limits the server accepts for uploads
+type UploadCapabilities struct {
+    AudioExtensions         []string `json:"audio_extensions"`
+    MaxUploadSize           int64    `json:"max_upload_size"`             // largest accepted archive in bytes
+    MaxUncompressedSize     int64    `json:"max_uncompressed_size"`       // largest total extracted size in bytes
+    MaxArchiveEntries       int      `json:"max_archive_entries"`
+    MaxAudioFilesPerProject int      `json:"max_audio_files_per_project"` // 0 for no limit
 }
 
 // ProjectFromZipRequest represents request to create project from ZIP
 type ProjectFromZipRequest struct {
     Name        string `json:"name" binding:"required"`
     Description string `json:"description,omitempty"`
-    Genre       string `json:"genre,omitempty"`
-    BPM         int    `json:"bpm,omitempty"`
-    Key         string `json:"key,omitempty"`
+    Genre       string `json:"genre,omitempty" binding:"omitempty,max=50"`
+    BPM         int    `json:"bpm,omitempty" binding:"omitempty,gte=20,lte=300"`
+    Key         string `json:"key,omitempty" binding:"omitempty,musical_key"` // e.g. "C", "F#m", "Bbm"
+}
+
+// Settings returns the project settings given by the request, with the key written the
+// way project settings write it, e.g. "F# minor". Key must already be valid.
+func (r *ProjectFromZipRequest) Settings() ProjectSettings {
+    settings := ProjectSettings{Tempo: r.BPM}
+    if key, ok := NormalizeMusicalKey(r.Key); ok {
+        if tonic := strings.TrimSuffix(key, "m"); tonic != key {
+            settings.Key = tonic + " minor"
+        } else {
+            settings.Key = key + " major"
+        }
+    }
+    return settings
+}
+
+// MusicalTonics are the notes a musical key may start on
+var MusicalTonics = []string{"C", "C#", "Db", "D", "D#", "Eb", "E", "F", "F#", "Gb", "G", "G#", "Ab", "A", "A#", "Bb", "B"}
+
+// NormalizeMusicalKey reads a key written as its tonic followed by "m" for minor keys, in
+// any case, and returns it with conventional casing: "c#m" becomes "C#m". It reports false
+// for anything else.
+func NormalizeMusicalKey(key string) (string, bool) {
+    key = strings.TrimSpace(key)
+    if key == "" {
+        return "", false
+    }
+
+    // The note letter is upper case and the flat sign and minor suffix lower case
+    normalized := strings.ToUpper(key[:1]) + strings.ToLower(key[1:])
+    tonic := strings.TrimSuffix(normalized, "m")
+    for _, candidate := range MusicalTonics {
+        if tonic == candidate {
+            return normalized, true
+        }
+    }
+    return "", false
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus is the lifecycle state of a background job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCanceled  JobStatus = "canceled"
+)
+
+// Valid reports whether the status is one of the known job states
+func (s JobStatus) Valid() bool {
+	switch s {
+	case JobStatusPending, JobStatusRunning, JobStatusCompleted, JobStatusFailed, JobStatusCanceled:
+		return true
+	}
+	return false
+}
+
+// Finished reports whether a job in this status has stopped for good
+func (s JobStatus) Finished() bool {
+	return s == JobStatusCompleted || s == JobStatusFailed || s == JobStatusCanceled
+}
+
+// ExtractionJob is a ZIP extraction running in the background for a user
+type ExtractionJob struct {
+	ID         uuid.UUID             `json:"id"`
+	UserID     uuid.UUID             `json:"user_id"`
+	FileID     uuid.UUID             `json:"file_id"`
+	ProjectID  uuid.UUID             `json:"project_id"`
+	Status     JobStatus             `json:"status"`
+	Processed  int                   `json:"processed_entries"` // archive entries handled so far
+	Total      int                   `json:"total_entries"`     // 0 until the archive is opened
+	Summary    *ExtractionJobSummary `json:"summary,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+}
+
+// ExtractionJobSummary condenses the result of a finished extraction job
+type ExtractionJobSummary struct {
+	TotalFiles   int   `json:"total_files"`
+	AudioFiles   int   `json:"audio_files"`
+	SkippedFiles int   `json:"skipped_files"`
+	TotalSize    int64 `json:"total_size"`
+}
@@ -4,19 +4,27 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organization visibility values
+const (
+	OrganizationVisibilityPublic  = "public"
+	OrganizationVisibilityPrivate = "private"
 )
 
 type Organization struct {
-	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name        string    `json:"name" gorm:"not null"`
-	Slug        string    `json:"slug" gorm:"unique;not null"`
-	Description string    `json:"description"`
-	AvatarURL   string    `json:"avatar_url"`
-	Website     string    `json:"website"`
-	Visibility  string    `json:"visibility" gorm:"default:'public'"`
-	CreatedBy   uuid.UUID `json:"created_by" gorm:"type:uuid"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name        string         `json:"name" gorm:"not null"`
+	Slug        string         `json:"slug" gorm:"unique;not null"`
+	Description string         `json:"description"`
+	AvatarURL   string         `json:"avatar_url"`
+	Website     string         `json:"website"`
+	Visibility  string         `json:"visibility" gorm:"default:'public'"`
+	CreatedBy   uuid.UUID      `json:"created_by" gorm:"type:uuid"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Creator  User                 `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
@@ -24,16 +32,44 @@ type Organization struct {
 	Projects []Project            `json:"projects,omitempty" gorm:"foreignKey:OrganizationID"`
 }
 
+// PublicOrganizationPage is a page of public organizations
+type PublicOrganizationPage struct {
+	Organizations []*Organization `json:"organizations"`
+	Total         int64           `json:"total"`
+	Limit         int             `json:"limit"`
+	Offset        int             `json:"offset"`
+}
+
+// BeforeCreate hook to set timestamps
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	setCreateTimestamps(&o.CreatedAt, &o.UpdatedAt)
+	return nil
+}
+
+// BeforeUpdate hook to set the update time
+func (o *Organization) BeforeUpdate(tx *gorm.DB) error {
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// Roles a member can have in an organization
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
 // OrganizationMember represents the relationship between users and organizations
 type OrganizationMember struct {
-	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null"`
-	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
-	Role           string     `json:"role" gorm:"default:'member'"` // owner, admin, member
-	InvitedAt      time.Time  `json:"invited_at"`
-	JoinedAt       *time.Time `json:"joined_at"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID      `json:"organization_id" gorm:"type:uuid;not null"`
+	UserID         uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
+	Role           string         `json:"role" gorm:"default:'member'"` // owner, admin, member
+	InvitedAt      time.Time      `json:"invited_at"`
+	JoinedAt       *time.Time     `json:"joined_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Organization Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
@@ -18,7 +18,8 @@ type Project struct {
 	CreatedBy      uuid.UUID       `json:"created_by" gorm:"type:uuid;not null"`
 	IsPublic       bool            `json:"is_public" gorm:"default:false"`
 	CurrentBranch  string          `json:"current_branch" gorm:"default:'main'"`
-	Settings       ProjectSettings `json:"settings" gorm:"type:jsonb"`
+	Settings       ProjectSettings `json:"settings" gorm:"type:jsonb;serializer:json"`
+	StoragePurged  bool            `json:"storage_purged" gorm:"default:false"` // files removed from disk by an admin
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt  `json:"-" gorm:"index"`
@@ -39,6 +40,7 @@ type ProjectSettings struct {
 	Tempo         int    `json:"tempo"`
 	TimeSignature string `json:"time_signature"`
 	Key           string `json:"key"`
+	WebhookURL    string `json:"webhook_url"` // receives project events such as extraction.completed
 }
 
 // ProjectCollaborator represents the relationship between users and projects
@@ -57,14 +59,126 @@ type ProjectCollaborator struct {
 	User    User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
-// BeforeCreate hook to set ID
+// Roles a user can have on a project
+const (
+	ProjectRoleOwner        = "owner"
+	ProjectRoleAdmin        = "admin"
+	ProjectRoleCollaborator = "collaborator"
+	ProjectRoleViewer       = "viewer"
+)
+
+// UserProject is a project listed for a user, with the user's role in it
+type UserProject struct {
+	Project
+	Role           string    `json:"role"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+// UserProjectPage is a page of a user's projects
+type UserProjectPage struct {
+	Projects []*UserProject `json:"projects"`
+	Total    int64          `json:"total"`
+	Limit    int            `json:"limit"`
+	Offset   int            `json:"offset"`
+}
+
+// UserProjectFavorite marks a project as one of a user's favorites
+type UserProjectFavorite struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Project Project `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
+}
+
+// FavoriteProject is a project as listed among a user's favorites
+type FavoriteProject struct {
+	Project
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+// Orders of the public project listing
+const (
+	PublicProjectSortRecent  = "recent"  // newest first
+	PublicProjectSortPopular = "popular" // most collaborators first, then most files
+)
+
+// PublicProject is a public project as listed for discovery
+type PublicProject struct {
+	Project
+	CollaboratorCount int64 `json:"collaborator_count"`
+	FileCount         int64 `json:"file_count"`
+}
+
+// PublicProjectPage is a page of public projects
+type PublicProjectPage struct {
+	Projects []*PublicProject `json:"projects"`
+	Total    int64            `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+// CreateProjectRequest is the body of a project creation. Settings are changed afterwards
+// through the project settings endpoint.
+type CreateProjectRequest struct {
+	Name           string     `json:"name" binding:"required,max=255"`
+	Description    string     `json:"description" binding:"max=2000"`
+	IsPublic       bool       `json:"is_public"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+}
+
+// UpdateProjectRequest lists the project fields to change; omitted fields are kept
+type UpdateProjectRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=2000"`
+	IsPublic    *bool   `json:"is_public,omitempty"`
+}
+
+// BatchDeleteProjectsRequest lists the projects to delete at once
+type BatchDeleteProjectsRequest struct {
+	ProjectIDs []uuid.UUID `json:"project_ids" binding:"required,min=1,max=100"`
+}
+
+// Outcomes for one project of a batch delete
+const (
+	BatchDeleteDeleted   = "deleted"
+	BatchDeleteForbidden = "forbidden"
+	BatchDeleteNotFound  = "not_found"
+)
+
+// BatchDeleteResult is the outcome of a batch delete for one project
+type BatchDeleteResult struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Status    string    `json:"status"`
+}
+
+// BeforeCreate hook to set ID and timestamps
 func (p *Project) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
 		p.ID = uuid.New()
 	}
+	setCreateTimestamps(&p.CreatedAt, &p.UpdatedAt)
+	return nil
+}
+
+// BeforeUpdate hook to set the update time
+func (p *Project) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now()
 	return nil
 }
 
+// setCreateTimestamps fills in the creation and update times of a new record, keeping any
+// already set, so that neither is ever stored as zero
+func setCreateTimestamps(createdAt, updatedAt *time.Time) {
+	if createdAt.IsZero() {
+		*createdAt = time.Now()
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = *createdAt
+	}
+}
+
 // BeforeCreate hook for ProjectCollaborator
 func (pc *ProjectCollaborator) BeforeCreate(tx *gorm.DB) error {
 	if pc.ID == uuid.Nil {
@@ -0,0 +1,38 @@
+package models
+
+import "github.com/google/uuid"
+
+// StorageUsageBreakdown counts files and bytes for one category of files
+type StorageUsageBreakdown struct {
+	Count int64 `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+// StorageUsageFile is a single entry in the list of a project's largest files
+type StorageUsageFile struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Path string    `json:"path"`
+	Size int64     `json:"size"`
+}
+
+// StorageUsage summarizes the disk space used by a project's files
+type StorageUsage struct {
+	ProjectID    uuid.UUID             `json:"project_id"`
+	TotalBytes   int64                 `json:"total_bytes"`
+	FileCount    int64                 `json:"file_count"`
+	Audio        StorageUsageBreakdown `json:"audio"`
+	Other        StorageUsageBreakdown `json:"other"`
+	LargestFiles []StorageUsageFile    `json:"largest_files"`
+}
+
+// ProjectStorage lists what a project keeps on disk: its extracted files and the archives
+// that were extracted into it
+type ProjectStorage struct {
+	ProjectID     uuid.UUID `json:"project_id"`
+	TotalBytes    int64     `json:"total_bytes"`
+	FileCount     int       `json:"file_count"`
+	Paths         []string  `json:"paths"`    // extracted files, relative to the project directory
+	Archives      []string  `json:"archives"` // stored archive paths
+	StoragePurged bool      `json:"storage_purged"`
+}
@@ -5,20 +5,34 @@ import (
     "github.com/google/uuid"
 )
 
+// Track is a song or stem of a project. A track created from an audio file mirrors the
+// BPM, key, genre and duration of the file's AudioMetadata, which is the source of truth
+// for them and is copied over whenever it changes.
 type Track struct {
-    ID          uuid.UUID `json:"id" db:"id"`
-    ProjectID   uuid.UUID `json:"project_id" db:"project_id"`
+    ID          uuid.UUID `json:"id" db:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    ProjectID   uuid.UUID `json:"project_id" db:"project_id" gorm:"type:uuid;not null;index"`
     Name        string    `json:"name" db:"name"`
     Artist      string    `json:"artist" db:"artist"`
     Duration    int       `json:"duration" db:"duration"` // in seconds
+    // DurationUnknown is set when the duration couldn't be decoded from the audio file
+    DurationUnknown bool  `json:"duration_unknown" db:"duration_unknown"`
     BPM         *int      `json:"bpm,omitempty" db:"bpm"`
     Key         *string   `json:"key,omitempty" db:"key"`
     Genre       *string   `json:"genre,omitempty" db:"genre"`
-    FileID      *uuid.UUID `json:"file_id,omitempty" db:"file_id"`
-    LyricsID    *uuid.UUID `json:"lyrics_id,omitempty" db:"lyrics_id"`
+    FileID      *uuid.UUID `json:"file_id,omitempty" db:"file_id" gorm:"type:uuid"`
+    LyricsID    *uuid.UUID `json:"lyrics_id,omitempty" db:"lyrics_id" gorm:"type:uuid"`
     Status      string    `json:"status" db:"status"` // draft, recording, mixing, mastered, released
-    CreatedBy   uuid.UUID `json:"created_by" db:"created_by"`
+    CreatedBy   uuid.UUID `json:"created_by" db:"created_by" gorm:"type:uuid"`
     CreatedAt   time.Time `json:"created_at" db:"created_at"`
     UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
     DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
-}
\ No newline at end of file
+}
+
+// Track statuses
+const (
+    TrackStatusDraft     = "draft"
+    TrackStatusRecording = "recording"
+    TrackStatusMixing    = "mixing"
+    TrackStatusMastered  = "mastered"
+    TrackStatusReleased  = "released"
+)
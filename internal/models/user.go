@@ -9,32 +9,88 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	KeycloakID string         `json:"keycloak_id" gorm:"uniqueIndex"`
-	Email      string         `json:"email" gorm:"uniqueIndex;not null"`
-	Username   string         `json:"username" gorm:"uniqueIndex;not null"`
-	FirstName  string         `json:"first_name"`
-	LastName   string         `json:"last_name"`
-	Avatar     string         `json:"avatar"`
-	Password   string         `json:"-" gorm:"not null"` // Never include in JSON
-	IsActive   bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	KeycloakID    string         `json:"keycloak_id" gorm:"uniqueIndex"`
+	Email         string         `json:"email" gorm:"uniqueIndex;not null"`
+	EmailVerified bool           `json:"email_verified" gorm:"default:false"`
+	Username      string         `json:"username" gorm:"uniqueIndex;not null"`
+	FirstName     string         `json:"first_name"`
+	LastName      string         `json:"last_name"`
+	Avatar        string         `json:"avatar"`
+	Password      string         `json:"-" gorm:"not null"` // Never include in JSON
+	IsActive      bool           `json:"is_active" gorm:"default:true"`
+	LastLoginAt   *time.Time     `json:"last_login_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	OwnedProjects  []Project             `json:"owned_projects,omitempty" gorm:"foreignKey:OwnerID"`
 	Collaborations []ProjectCollaborator `json:"collaborations,omitempty" gorm:"foreignKey:UserID"`
 }
 
-// BeforeCreate hook to set ID
+// UserProfileUpdate holds the profile fields a user can change themselves.
+// Empty fields are left unchanged.
+type UserProfileUpdate struct {
+	Email     string `json:"email" binding:"omitempty,email"`
+	FirstName string `json:"first_name" binding:"omitempty,max=100"`
+	LastName  string `json:"last_name" binding:"omitempty,max=100"`
+	Avatar    string `json:"avatar" binding:"omitempty,url"`
+}
+
+// LoginRequest holds the credentials of a password login
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"` // username or email
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse carries the Keycloak tokens of a login along with the signed-in user
+type LoginResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	User         *User  `json:"user"`
+}
+
+// BeforeCreate hook to set ID and timestamps
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	setCreateTimestamps(&u.CreatedAt, &u.UpdatedAt)
 	return nil
 }
 
+// BeforeUpdate hook to set the update time
+func (u *User) BeforeUpdate(tx *gorm.DB) error {
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// UserSummary counts a user's resources for their dashboard
+type UserSummary struct {
+	ProjectCount        int64     `json:"project_count"`         // projects the user owns, created or collaborates on
+	OrganizationCount   int64     `json:"organization_count"`    // organizations the user is a member of
+	StorageBytes        int64     `json:"storage_bytes"`         // size of the files in the projects the user owns
+	RecentActivityCount int64     `json:"recent_activity_count"` // activity in the user's projects since ActivitySince
+	ActivitySince       time.Time `json:"activity_since"`
+}
+
+// PublicUserProfile is what anyone may see of a user, e.g. to link to them or mention them
+type PublicUserProfile struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Avatar    string    `json:"avatar"`
+}
+
+// CanSignIn reports whether the account may use the API: it is active and not deleted
+func (u *User) CanSignIn() bool {
+	return u.IsActive && !u.DeletedAt.Valid
+}
+
 // GetFullName returns the user's full name
 func (u *User) GetFullName() string {
 	if u.FirstName != "" && u.LastName != "" {
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"collabhub-music-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// branchRepository implements the BranchRepositoryInterface
+type branchRepository struct {
+	db *gorm.DB
+}
+
+// NewBranchRepository creates a new instance of branchRepository
+func NewBranchRepository(db *gorm.DB) BranchRepositoryInterface {
+	return &branchRepository{db: db}
+}
+
+// Create adds a new branch to the database
+func (r *branchRepository) Create(branch *models.Branch) error {
+	return r.db.Create(branch).Error
+}
+
+// GetByID retrieves a branch by ID
+func (r *branchRepository) GetByID(id uuid.UUID) (*models.Branch, error) {
+	var branch models.Branch
+	err := r.db.First(&branch, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+// GetByProjectID retrieves all branches of a project, default branch first
+func (r *branchRepository) GetByProjectID(projectID uuid.UUID) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.db.Where("project_id = ?", projectID).
+		Order("is_default DESC, name ASC").
+		Find(&branches).Error
+	return branches, err
+}
+
+// Update updates a branch in the database
+func (r *branchRepository) Update(branch *models.Branch) error {
+	return r.db.Save(branch).Error
+}
+
+// Delete soft-deletes a branch
+func (r *branchRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Branch{}, "id = ?", id).Error
+}
+
+// SetDefault makes a branch the default one of its project, clearing the flag on the others
+func (r *branchRepository) SetDefault(branchID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var branch models.Branch
+		if err := tx.First(&branch, "id = ?", branchID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Branch{}).
+			Where("project_id = ? AND id <> ?", branch.ProjectID, branchID).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&branch).Update("is_default", true).Error
+	})
+}
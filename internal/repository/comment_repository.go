@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"collabhub-music-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// commentRepository implements the CommentRepositoryInterface
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new instance of commentRepository
+func NewCommentRepository(db *gorm.DB) CommentRepositoryInterface {
+	return &commentRepository{db: db}
+}
+
+// Create adds a new comment to the database
+func (r *commentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// GetByID retrieves a comment by ID
+func (r *commentRepository) GetByID(id uuid.UUID) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetByProjectID retrieves a page of a project's comments and replies with their authors,
+// oldest first, and the total number of comments
+func (r *commentRepository) GetByProjectID(projectID uuid.UUID, limit, offset int) ([]*models.Comment, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.Comment{}).Where("project_id = ?", projectID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []*models.Comment
+	err := r.db.Preload("Author").
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&comments).Error
+	return comments, total, err
+}
+
+// Delete soft-deletes a comment together with its replies
+func (r *commentRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Comment{}, "id = ? OR parent_id = ?", id, id).Error
+}
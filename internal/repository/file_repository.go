@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"collabhub-music-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fileRepository implements the FileRepositoryInterface
+type fileRepository struct {
+	db *gorm.DB
+}
+
+// NewFileRepository creates a new instance of fileRepository
+func NewFileRepository(db *gorm.DB) FileRepositoryInterface {
+	return &fileRepository{db: db}
+}
+
+// Create adds a new file to the database
+func (r *fileRepository) Create(file *models.File) error {
+	return r.db.Create(file).Error
+}
+
+// GetByID retrieves a file by ID with its audio metadata
+func (r *fileRepository) GetByID(id uuid.UUID) (*models.File, error) {
+	var file models.File
+	err := r.db.Preload("AudioMetadata").First(&file, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetByProjectID retrieves all files of a project with their audio metadata
+func (r *fileRepository) GetByProjectID(projectID uuid.UUID) ([]*models.File, error) {
+	var files []*models.File
+	err := r.db.Preload("AudioMetadata").
+		Where("project_id = ?", projectID).
+		Order("path ASC").
+		Find(&files).Error
+	return files, err
+}
+
+// GetByBranchID retrieves all files of a branch with their audio metadata
+func (r *fileRepository) GetByBranchID(branchID uuid.UUID) ([]*models.File, error) {
+	var files []*models.File
+	err := r.db.Preload("AudioMetadata").
+		Where("branch_id = ?", branchID).
+		Order("path ASC").
+		Find(&files).Error
+	return files, err
+}
+
+// CountByBranch counts the files on each branch of a project with one grouped query.
+// Branches without files are left out.
+func (r *fileRepository) CountByBranch(projectID uuid.UUID) ([]models.BranchFileStats, error) {
+	var stats []models.BranchFileStats
+	err := r.db.Model(&models.File{}).
+		Select("branch_id, COUNT(*) AS file_count, MAX(updated_at) AS last_updated_at").
+		Where("project_id = ?", projectID).
+		Group("branch_id").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// Update updates a file in the database
+func (r *fileRepository) Update(file *models.File) error {
+	return r.db.Save(file).Error
+}
+
+// SaveBatch creates and updates a set of files in a single transaction
+func (r *fileRepository) SaveBatch(created, updated []*models.File) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, file := range created {
+			if err := tx.Create(file).Error; err != nil {
+				return err
+			}
+		}
+		for _, file := range updated {
+			if err := tx.Save(file).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SyncBatch creates a set of files and soft-deletes another in a single transaction
+func (r *fileRepository) SyncBatch(created []*models.File, deletedIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, file := range created {
+			if err := tx.Create(file).Error; err != nil {
+				return err
+			}
+		}
+		if len(deletedIDs) > 0 {
+			if err := tx.Delete(&models.File{}, "id IN ?", deletedIDs).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete soft-deletes a file, returning gorm.ErrRecordNotFound if no live file has that ID
+func (r *fileRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&models.File{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateVersion adds a new version of a file
+func (r *fileRepository) CreateVersion(version *models.FileVersion) error {
+	return r.db.Create(version).Error
+}
+
+// GetVersions gets all versions of a file, newest first. Versions of a soft-deleted
+// file are not returned, since FileVersion has no deleted_at of its own.
+func (r *fileRepository) GetVersions(fileID uuid.UUID) ([]*models.FileVersion, error) {
+	var versions []*models.FileVersion
+	err := r.db.Joins("JOIN files ON files.id = file_versions.file_id AND files.deleted_at IS NULL").
+		Where("file_versions.file_id = ?", fileID).
+		Order("file_versions.version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// GetVersion retrieves a single version of a file by its version number
+func (r *fileRepository) GetVersion(fileID uuid.UUID, version int) (*models.FileVersion, error) {
+	var fileVersion models.FileVersion
+	err := r.db.First(&fileVersion, "file_id = ? AND version = ?", fileID, version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &fileVersion, nil
+}
+
+// CreateAudioMetadata adds audio metadata for a file
+func (r *fileRepository) CreateAudioMetadata(metadata *models.AudioMetadata) error {
+	return r.db.Create(metadata).Error
+}
+
+// UpdateAudioMetadata updates the audio metadata of a file
+func (r *fileRepository) UpdateAudioMetadata(metadata *models.AudioMetadata) error {
+	return r.db.Save(metadata).Error
+}
+
+// SaveAudioMetadataBatch creates and updates the audio metadata of a set of files in a
+// single transaction
+func (r *fileRepository) SaveAudioMetadataBatch(created, updated []*models.AudioMetadata) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, metadata := range created {
+			if err := tx.Create(metadata).Error; err != nil {
+				return err
+			}
+		}
+		for _, metadata := range updated {
+			if err := tx.Save(metadata).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
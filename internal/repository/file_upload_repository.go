@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"collabhub-music-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fileUploadRepository implements the FileUploadRepositoryInterface
+type fileUploadRepository struct {
+	db *gorm.DB
+}
+
+// NewFileUploadRepository creates a new instance of fileUploadRepository
+func NewFileUploadRepository(db *gorm.DB) FileUploadRepositoryInterface {
+	return &fileUploadRepository{db: db}
+}
+
+// Create records a new upload
+func (r *fileUploadRepository) Create(upload *models.FileUpload) error {
+	return r.db.Create(upload).Error
+}
+
+// GetByID retrieves an upload by ID
+func (r *fileUploadRepository) GetByID(id uuid.UUID) (*models.FileUpload, error) {
+	var upload models.FileUpload
+	err := r.db.First(&upload, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// GetByUserID gets a page of a user's uploads, newest first, along with the total count
+func (r *fileUploadRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]*models.FileUpload, int64, error) {
+	query := r.db.Model(&models.FileUpload{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var uploads []*models.FileUpload
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&uploads).Error
+	return uploads, total, err
+}
+
+// GetByProjectID gets the uploads extracted into a project, oldest first
+func (r *fileUploadRepository) GetByProjectID(projectID uuid.UUID) ([]*models.FileUpload, error) {
+	var uploads []*models.FileUpload
+	err := r.db.Where("project_id = ?", projectID).Order("created_at ASC").Find(&uploads).Error
+	return uploads, err
+}
+
+// MarkExtracted records that an upload was extracted into a project
+func (r *fileUploadRepository) MarkExtracted(id, projectID uuid.UUID) error {
+	return r.db.Model(&models.FileUpload{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"is_extracted": true,
+		"project_id":   projectID,
+	}).Error
+}
+
+// Delete removes an upload record
+func (r *fileUploadRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.FileUpload{}, "id = ?", id).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"collabhub-music-backend/internal/models"
 
 	"github.com/google/uuid"
@@ -17,8 +19,12 @@ type UserRepositoryInterface interface {
 	GetByID(id uuid.UUID) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByUsername(username string) (*models.User, error)
+	GetByKeycloakID(keycloakID string) (*models.User, error)
 	Update(user *models.User) error
+	UpdateLastLogin(id uuid.UUID, at time.Time) error
 	Delete(id uuid.UUID) error
+	Restore(id uuid.UUID) error
+	Search(query string, includeDeleted bool, limit, offset int) ([]*models.User, int64, error)
 }
 
 // ProjectRepositoryInterface defines methods for project repository
@@ -26,23 +32,40 @@ type ProjectRepositoryInterface interface {
 	Create(project *models.Project) error
 	GetByID(id uuid.UUID) (*models.Project, error)
 	GetByUserID(userID uuid.UUID) ([]*models.Project, error)
+	GetByOrganizationID(organizationID uuid.UUID, limit, offset int) ([]*models.Project, int64, error)
+	GetUserProjects(userID uuid.UUID, role string, limit, offset int) ([]*models.UserProject, int64, error)
+	GetUserSummary(userID uuid.UUID, activitySince time.Time) (*models.UserSummary, error)
+	GetPublic(sort string, limit, offset int) ([]*models.PublicProject, int64, error)
 	Update(project *models.Project) error
+	UpdateSettings(projectID uuid.UUID, settings models.ProjectSettings) error
 	Delete(id uuid.UUID) error
+	DeleteBatch(ids []uuid.UUID) error
 	AddCollaborator(projectCollaborator *models.ProjectCollaborator) error
+	MarkCollaboratorJoined(collaboratorID uuid.UUID, joinedAt time.Time) error
 	RemoveCollaborator(projectID, userID uuid.UUID) error
 	GetCollaborators(projectID uuid.UUID) ([]*models.ProjectCollaborator, error)
+	GetActivity(projectID uuid.UUID, since *time.Time, limit, offset int) ([]*models.ActivityEvent, error)
+	GetStorageUsage(projectID uuid.UUID, largest int) (*models.StorageUsage, error)
+	MarkStoragePurged(projectID uuid.UUID) error
+	AddFavorite(userID, projectID uuid.UUID) error
+	RemoveFavorite(userID, projectID uuid.UUID) error
+	GetFavorites(userID uuid.UUID) ([]*models.UserProjectFavorite, error)
 }
 
 // OrganizationRepositoryInterface defines methods for organization repository
 type OrganizationRepositoryInterface interface {
 	Create(organization *models.Organization) error
 	GetByID(id uuid.UUID) (*models.Organization, error)
+	GetByName(name string) (*models.Organization, error)
 	GetByUserID(userID uuid.UUID) ([]*models.Organization, error)
+	CountByUserID(userID uuid.UUID) (int64, error)
 	Update(organization *models.Organization) error
 	Delete(id uuid.UUID) error
 	AddMember(member *models.OrganizationMember) error
 	RemoveMember(organizationID, userID uuid.UUID) error
 	GetMembers(organizationID uuid.UUID) ([]*models.OrganizationMember, error)
+	GetMembersPage(organizationID uuid.UUID, limit, offset int) ([]*models.OrganizationMember, int64, error)
+	SearchPublic(query string, limit, offset int) ([]*models.Organization, int64, error)
 }
 
 // FileRepositoryInterface defines methods for file repository
@@ -51,12 +74,34 @@ type FileRepositoryInterface interface {
 	GetByID(id uuid.UUID) (*models.File, error)
 	GetByProjectID(projectID uuid.UUID) ([]*models.File, error)
 	GetByBranchID(branchID uuid.UUID) ([]*models.File, error)
+	CountByBranch(projectID uuid.UUID) ([]models.BranchFileStats, error)
 	Update(file *models.File) error
 	Delete(id uuid.UUID) error
 	CreateVersion(version *models.FileVersion) error
 	GetVersions(fileID uuid.UUID) ([]*models.FileVersion, error)
+	GetVersion(fileID uuid.UUID, version int) (*models.FileVersion, error)
 	CreateAudioMetadata(metadata *models.AudioMetadata) error
 	UpdateAudioMetadata(metadata *models.AudioMetadata) error
+	SaveAudioMetadataBatch(created, updated []*models.AudioMetadata) error
+	SaveBatch(created, updated []*models.File) error
+	SyncBatch(created []*models.File, deletedIDs []uuid.UUID) error
+}
+
+// FileUploadRepositoryInterface defines methods for uploaded archive repository
+type FileUploadRepositoryInterface interface {
+	Create(upload *models.FileUpload) error
+	GetByID(id uuid.UUID) (*models.FileUpload, error)
+	GetByUserID(userID uuid.UUID, limit, offset int) ([]*models.FileUpload, int64, error)
+	GetByProjectID(projectID uuid.UUID) ([]*models.FileUpload, error)
+	MarkExtracted(id, projectID uuid.UUID) error
+	Delete(id uuid.UUID) error
+}
+
+// TrackRepositoryInterface defines methods for track repository
+type TrackRepositoryInterface interface {
+	CreateBatch(tracks []*models.Track) error
+	GetByProjectID(projectID uuid.UUID) ([]*models.Track, error)
+	Update(track *models.Track) error
 }
 
 // BranchRepositoryInterface defines methods for branch repository
@@ -68,3 +113,11 @@ type BranchRepositoryInterface interface {
 	Delete(id uuid.UUID) error
 	SetDefault(branchID uuid.UUID) error
 }
+
+// CommentRepositoryInterface defines methods for comment repository
+type CommentRepositoryInterface interface {
+	Create(comment *models.Comment) error
+	GetByID(id uuid.UUID) (*models.Comment, error)
+	GetByProjectID(projectID uuid.UUID, limit, offset int) ([]*models.Comment, int64, error)
+	Delete(id uuid.UUID) error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"strings"
+
 	"collabhub-music-backend/internal/models"
 
 	"github.com/google/uuid"
@@ -32,21 +34,42 @@ func (r *organizationRepository) GetByID(id uuid.UUID) (*models.Organization, er
 	return &organization, nil
 }
 
+// GetByName retrieves an organization by its exact name
+func (r *organizationRepository) GetByName(name string) (*models.Organization, error) {
+	var organization models.Organization
+	err := r.db.Preload("Creator").First(&organization, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
 // GetByUserID retrieves organizations by user ID
 func (r *organizationRepository) GetByUserID(userID uuid.UUID) ([]*models.Organization, error) {
 	var organizations []*models.Organization
-	err := r.db.Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+	// Joins bypass GORM's soft-delete scope for the joined table, so filter members explicitly
+	err := r.db.Joins("JOIN organization_members ON organization_members.organization_id = organizations.id AND organization_members.deleted_at IS NULL").
 		Where("organization_members.user_id = ?", userID).
 		Find(&organizations).Error
 	return organizations, err
 }
 
+// CountByUserID counts the organizations a user is a member of
+func (r *organizationRepository) CountByUserID(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Organization{}).
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id AND organization_members.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
 // Update updates an organization in the database
 func (r *organizationRepository) Update(organization *models.Organization) error {
 	return r.db.Save(organization).Error
 }
 
-// Delete deletes an organization from the database
+// Delete soft-deletes an organization; rows stay in the table with deleted_at set
 func (r *organizationRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Organization{}, id).Error
 }
@@ -64,6 +87,50 @@ func (r *organizationRepository) RemoveMember(organizationID, userID uuid.UUID)
 // GetMembers gets all members for an organization
 func (r *organizationRepository) GetMembers(organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
 	var members []*models.OrganizationMember
-	err := r.db.Preload("User").Where("organization_id = ?", organizationID).Find(&members).Error
+	err := r.membersQuery(organizationID).Preload("User").
+		Order("created_at ASC").
+		Find(&members).Error
 	return members, err
 }
+
+// GetMembersPage returns one page of an organization's members with their users, in the
+// order of GetMembers, along with the total number of members
+func (r *organizationRepository) GetMembersPage(organizationID uuid.UUID, limit, offset int) ([]*models.OrganizationMember, int64, error) {
+	var total int64
+	if err := r.membersQuery(organizationID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []*models.OrganizationMember
+	err := r.membersQuery(organizationID).Preload("User").
+		Order("created_at ASC").
+		Limit(limit).Offset(offset).
+		Find(&members).Error
+	return members, total, err
+}
+
+// membersQuery selects the members of an organization
+func (r *organizationRepository) membersQuery(organizationID uuid.UUID) *gorm.DB {
+	return r.db.Model(&models.OrganizationMember{}).Where("organization_id = ?", organizationID)
+}
+
+// SearchPublic finds public organizations whose name or slug contains the query
+// (case-insensitive) and returns one page of them by name along with the total number of matches
+func (r *organizationRepository) SearchPublic(query string, limit, offset int) ([]*models.Organization, int64, error) {
+	// Organizations without a visibility predate private ones and are public
+	db := r.db.Model(&models.Organization{}).Where("visibility IS DISTINCT FROM ?", models.OrganizationVisibilityPrivate)
+
+	if query = strings.TrimSpace(query); query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		db = db.Where("name ILIKE ? OR slug ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var organizations []*models.Organization
+	err := db.Order("name ASC").Limit(limit).Offset(offset).Find(&organizations).Error
+	return organizations, total, err
+}
@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"time"
+
 	"collabhub-music-backend/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // projectRepository implements the ProjectRepositoryInterface
@@ -39,21 +42,255 @@ func (r *projectRepository) GetByUserID(userID uuid.UUID) ([]*models.Project, er
 	return projects, err
 }
 
+// GetByOrganizationID gets a page of an organization's projects, newest first, along with the total count
+func (r *projectRepository) GetByOrganizationID(organizationID uuid.UUID, limit, offset int) ([]*models.Project, int64, error) {
+	query := r.db.Model(&models.Project{}).Where("organization_id = ?", organizationID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var projects []*models.Project
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&projects).Error
+	return projects, total, err
+}
+
+// userProjectsFrom selects the projects a user owns, created or collaborates on, with the
+// user's role in each and the project's last activity: the latest update of the project
+// or of one of its files. Pending invitations don't count. An empty @role matches every role.
+const userProjectsFrom = `
+FROM (
+	SELECT p.id,
+		CASE WHEN p.owner_id = @user THEN 'owner' ELSE COALESCE(pc.role, 'collaborator') END AS role,
+		GREATEST(p.updated_at, COALESCE(
+			(SELECT MAX(f.updated_at) FROM files f WHERE f.project_id = p.id AND f.deleted_at IS NULL),
+			p.updated_at)) AS last_activity_at
+	FROM projects p
+	LEFT JOIN project_collaborators pc ON pc.project_id = p.id AND pc.user_id = @user AND pc.joined_at IS NOT NULL
+	WHERE p.deleted_at IS NULL AND (p.owner_id = @user OR p.created_by = @user OR pc.id IS NOT NULL)
+) AS user_projects
+WHERE @role = '' OR user_projects.role = @role`
+
+// GetUserProjects gets a page of the projects a user is a member of, most recently active
+// first, along with the total count. A non-empty role keeps only projects where the user
+// has that role.
+func (r *projectRepository) GetUserProjects(userID uuid.UUID, role string, limit, offset int) ([]*models.UserProject, int64, error) {
+	params := map[string]interface{}{
+		"user":   userID,
+		"role":   role,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*)"+userProjectsFrom, params).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ID             uuid.UUID
+		Role           string
+		LastActivityAt time.Time
+	}
+	err := r.db.Raw("SELECT user_projects.*"+userProjectsFrom+
+		" ORDER BY user_projects.last_activity_at DESC, user_projects.id LIMIT @limit OFFSET @offset", params).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []*models.UserProject{}, total, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var projects []*models.Project
+	if err := r.db.Preload("Owner").Where("id IN ?", ids).Find(&projects).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uuid.UUID]*models.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	// Keep the activity order of the first query
+	userProjects := make([]*models.UserProject, 0, len(rows))
+	for _, row := range rows {
+		project, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		userProjects = append(userProjects, &models.UserProject{
+			Project:        *project,
+			Role:           row.Role,
+			LastActivityAt: row.LastActivityAt,
+		})
+	}
+	return userProjects, total, nil
+}
+
+// userSummaryQuery counts, over the projects a user owns, created or joined as a
+// collaborator, the projects themselves, the bytes stored in the ones the user owns, and the
+// events of their activity feeds since @since
+const userSummaryQuery = `
+WITH user_projects AS (
+	SELECT p.id, p.owner_id = @user AS owned
+	FROM projects p
+	WHERE p.deleted_at IS NULL AND (p.owner_id = @user OR p.created_by = @user OR EXISTS (
+		SELECT 1 FROM project_collaborators pc
+		WHERE pc.project_id = p.id AND pc.user_id = @user AND pc.joined_at IS NOT NULL))
+)
+SELECT
+	(SELECT COUNT(*) FROM user_projects) AS project_count,
+	(SELECT COALESCE(SUM(f.size), 0)
+		FROM files f JOIN user_projects up ON up.id = f.project_id AND up.owned
+		WHERE f.deleted_at IS NULL) AS storage_bytes,
+	(SELECT COUNT(*) FROM (
+		SELECT f.created_at
+		FROM files f JOIN user_projects up ON up.id = f.project_id
+		WHERE f.deleted_at IS NULL
+		UNION ALL
+		SELECT fv.created_at
+		FROM file_versions fv JOIN files f ON f.id = fv.file_id JOIN user_projects up ON up.id = f.project_id
+		WHERE f.deleted_at IS NULL
+		UNION ALL
+		SELECT pc.joined_at
+		FROM project_collaborators pc JOIN user_projects up ON up.id = pc.project_id
+		WHERE pc.joined_at IS NOT NULL
+		UNION ALL
+		SELECT b.created_at
+		FROM branches b JOIN user_projects up ON up.id = b.project_id
+		WHERE b.deleted_at IS NULL
+	) AS activity WHERE activity.created_at > @since) AS recent_activity_count`
+
+// GetUserSummary counts a user's projects, the storage of the projects they own and the
+// activity in their projects since activitySince. The organization count is left to the
+// organization repository.
+func (r *projectRepository) GetUserSummary(userID uuid.UUID, activitySince time.Time) (*models.UserSummary, error) {
+	summary := &models.UserSummary{ActivitySince: activitySince}
+	err := r.db.Raw(userSummaryQuery, map[string]interface{}{
+		"user":  userID,
+		"since": activitySince,
+	}).Scan(summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// publicProjectOrders maps the orders of the public listing to their ORDER BY clause
+var publicProjectOrders = map[string]string{
+	models.PublicProjectSortRecent:  "p.created_at DESC, p.id",
+	models.PublicProjectSortPopular: "collaborator_count DESC, file_count DESC, p.created_at DESC, p.id",
+}
+
+// GetPublic gets a page of the public projects in the given order, along with the total
+// count. Each comes with its number of collaborators and of files.
+func (r *projectRepository) GetPublic(sort string, limit, offset int) ([]*models.PublicProject, int64, error) {
+	order, ok := publicProjectOrders[sort]
+	if !ok {
+		order = publicProjectOrders[models.PublicProjectSortRecent]
+	}
+
+	var total int64
+	if err := r.db.Model(&models.Project{}).Where("is_public = ?", true).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ID                uuid.UUID
+		CollaboratorCount int64
+		FileCount         int64
+	}
+	err := r.db.Raw(`SELECT p.id,
+			(SELECT COUNT(*) FROM project_collaborators pc WHERE pc.project_id = p.id) AS collaborator_count,
+			(SELECT COUNT(*) FROM files f WHERE f.project_id = p.id AND f.deleted_at IS NULL) AS file_count
+		FROM projects p
+		WHERE p.is_public AND p.deleted_at IS NULL
+		ORDER BY `+order+` LIMIT ? OFFSET ?`, limit, offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []*models.PublicProject{}, total, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var projects []*models.Project
+	if err := r.db.Preload("Owner").Where("id IN ?", ids).Find(&projects).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uuid.UUID]*models.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	// Keep the order of the first query
+	public := make([]*models.PublicProject, 0, len(rows))
+	for _, row := range rows {
+		project, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		public = append(public, &models.PublicProject{
+			Project:           *project,
+			CollaboratorCount: row.CollaboratorCount,
+			FileCount:         row.FileCount,
+		})
+	}
+	return public, total, nil
+}
+
 // Update updates a project in the database
 func (r *projectRepository) Update(project *models.Project) error {
 	return r.db.Save(project).Error
 }
 
+// UpdateSettings writes only the settings column of a project
+func (r *projectRepository) UpdateSettings(projectID uuid.UUID, settings models.ProjectSettings) error {
+	result := r.db.Model(&models.Project{}).Where("id = ?", projectID).
+		Select("settings").
+		Updates(&models.Project{Settings: settings})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // Delete deletes a project from the database
 func (r *projectRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Project{}, id).Error
 }
 
+// DeleteBatch soft-deletes several projects in one statement, so either all of them are
+// deleted or none is
+func (r *projectRepository) DeleteBatch(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Delete(&models.Project{}, "id IN ?", ids).Error
+}
+
 // AddCollaborator adds a collaborator to a project
 func (r *projectRepository) AddCollaborator(projectCollaborator *models.ProjectCollaborator) error {
 	return r.db.Create(projectCollaborator).Error
 }
 
+// MarkCollaboratorJoined sets the time a collaborator joined the project
+func (r *projectRepository) MarkCollaboratorJoined(collaboratorID uuid.UUID, joinedAt time.Time) error {
+	return r.db.Model(&models.ProjectCollaborator{}).Where("id = ?", collaboratorID).Update("joined_at", joinedAt).Error
+}
+
 // RemoveCollaborator removes a collaborator from a project
 func (r *projectRepository) RemoveCollaborator(projectID, userID uuid.UUID) error {
 	return r.db.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectCollaborator{}).Error
@@ -65,3 +302,128 @@ func (r *projectRepository) GetCollaborators(projectID uuid.UUID) ([]*models.Pro
 	err := r.db.Preload("User").Where("project_id = ?", projectID).Find(&collaborators).Error
 	return collaborators, err
 }
+
+// activityQuery unions the project's files, versions, collaborators who joined and branches
+// into a single feed; GetActivity orders and pages it
+const activityQuery = `
+SELECT activity.type, activity.target_id, activity.target_name, activity.actor_id,
+	users.username AS actor_username, activity.created_at
+FROM (
+	SELECT 'file_added' AS type, f.id AS target_id, f.name AS target_name, f.uploaded_by AS actor_id, f.created_at
+	FROM files f
+	WHERE f.project_id = @project AND f.deleted_at IS NULL
+	UNION ALL
+	SELECT 'version_created', fv.id, f.name, fv.created_by, fv.created_at
+	FROM file_versions fv JOIN files f ON f.id = fv.file_id
+	WHERE f.project_id = @project AND f.deleted_at IS NULL
+	UNION ALL
+	SELECT 'collaborator_joined', pc.id, '', pc.user_id, pc.joined_at
+	FROM project_collaborators pc
+	WHERE pc.project_id = @project AND pc.joined_at IS NOT NULL
+	UNION ALL
+	SELECT 'branch_created', b.id, b.name, b.created_by, b.created_at
+	FROM branches b
+	WHERE b.project_id = @project AND b.deleted_at IS NULL
+) AS activity
+LEFT JOIN users ON users.id = activity.actor_id
+WHERE @since::timestamptz IS NULL OR activity.created_at > @since`
+
+// GetActivity gets the activity feed for a project, newest first. Events after since come
+// oldest first instead, so paging through them never skips any that happen meanwhile and
+// the last one is where the next poll starts.
+func (r *projectRepository) GetActivity(projectID uuid.UUID, since *time.Time, limit, offset int) ([]*models.ActivityEvent, error) {
+	order := "DESC"
+	if since != nil {
+		order = "ASC"
+	}
+
+	var events []*models.ActivityEvent
+	query := activityQuery + "\nORDER BY activity.created_at " + order + ", activity.target_id\nLIMIT @limit OFFSET @offset"
+	err := r.db.Raw(query, map[string]interface{}{
+		"project": projectID,
+		"since":   since,
+		"limit":   limit,
+		"offset":  offset,
+	}).Scan(&events).Error
+	return events, err
+}
+
+// GetStorageUsage sums the sizes of a project's files, split between audio and other files,
+// and lists the largest ones
+func (r *projectRepository) GetStorageUsage(projectID uuid.UUID, largest int) (*models.StorageUsage, error) {
+	var totals []struct {
+		IsAudio bool
+		Count   int64
+		Bytes   int64
+	}
+	err := r.db.Model(&models.File{}).
+		Select("file_type = ? AS is_audio, COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes", "audio").
+		Where("project_id = ?", projectID).
+		Group("is_audio").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &models.StorageUsage{
+		ProjectID:    projectID,
+		LargestFiles: []models.StorageUsageFile{},
+	}
+	for _, total := range totals {
+		breakdown := models.StorageUsageBreakdown{Count: total.Count, Bytes: total.Bytes}
+		if total.IsAudio {
+			usage.Audio = breakdown
+		} else {
+			usage.Other = breakdown
+		}
+		usage.FileCount += total.Count
+		usage.TotalBytes += total.Bytes
+	}
+
+	err = r.db.Model(&models.File{}).
+		Select("id, name, path, size").
+		Where("project_id = ?", projectID).
+		Order("size DESC").
+		Limit(largest).
+		Scan(&usage.LargestFiles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// MarkStoragePurged flags a project whose files were removed from disk, leaving its rows
+func (r *projectRepository) MarkStoragePurged(projectID uuid.UUID) error {
+	result := r.db.Model(&models.Project{}).Where("id = ?", projectID).Update("storage_purged", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AddFavorite adds a project to a user's favorites; adding it again changes nothing
+func (r *projectRepository) AddFavorite(userID, projectID uuid.UUID) error {
+	favorite := &models.UserProjectFavorite{UserID: userID, ProjectID: projectID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(favorite).Error
+}
+
+// RemoveFavorite removes a project from a user's favorites, if it is one
+func (r *projectRepository) RemoveFavorite(userID, projectID uuid.UUID) error {
+	return r.db.Delete(&models.UserProjectFavorite{}, "user_id = ? AND project_id = ?", userID, projectID).Error
+}
+
+// GetFavorites gets a user's favorites with their projects and owners, most recently
+// favorited first. Favorites of deleted projects are left out.
+func (r *projectRepository) GetFavorites(userID uuid.UUID) ([]*models.UserProjectFavorite, error) {
+	var favorites []*models.UserProjectFavorite
+	err := r.db.Preload("Project.Owner").
+		Joins("JOIN projects ON projects.id = user_project_favorites.project_id AND projects.deleted_at IS NULL").
+		Where("user_project_favorites.user_id = ?", userID).
+		Order("user_project_favorites.created_at DESC").
+		Find(&favorites).Error
+	return favorites, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"collabhub-music-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// trackRepository implements the TrackRepositoryInterface
+type trackRepository struct {
+	db *gorm.DB
+}
+
+// NewTrackRepository creates a new instance of trackRepository
+func NewTrackRepository(db *gorm.DB) TrackRepositoryInterface {
+	return &trackRepository{db: db}
+}
+
+// CreateBatch creates a set of tracks in a single transaction
+func (r *trackRepository) CreateBatch(tracks []*models.Track) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+	return r.db.Create(tracks).Error
+}
+
+// GetByProjectID retrieves the tracks of a project in name order
+func (r *trackRepository) GetByProjectID(projectID uuid.UUID) ([]*models.Track, error) {
+	var tracks []*models.Track
+	err := r.db.Where("project_id = ? AND deleted_at IS NULL", projectID).Order("name").Find(&tracks).Error
+	return tracks, err
+}
+
+// Update saves a track
+func (r *trackRepository) Update(track *models.Track) error {
+	return r.db.Save(track).Error
+}
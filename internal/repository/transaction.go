@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Transactor runs functions in a database transaction. Repositories created on the tx
+// passed to fn, with NewRepositories(tx) or a single constructor such as
+// NewProjectRepository(tx), all write through it, so their changes are committed
+// together or not at all.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+}
+
+// gormTransactor implements the Transactor interface
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a Transactor for db
+func NewTransactor(db *gorm.DB) Transactor {
+	return &gormTransactor{db: db}
+}
+
+// WithTransaction commits the transaction if fn returns nil and rolls it back if fn
+// returns an error or panics. Transactions started by repositories inside fn become
+// savepoints of this one.
+func (t *gormTransactor) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return t.db.WithContext(ctx).Transaction(fn)
+}
+
+// Repositories bundles the repositories of one database handle, for services that
+// compose several of them in a transaction
+type Repositories struct {
+	Projects ProjectRepositoryInterface
+	Branches BranchRepositoryInterface
+	Files    FileRepositoryInterface
+	Tracks   TrackRepositoryInterface
+}
+
+// NewRepositories creates the repositories on db, which may be a transaction
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Projects: NewProjectRepository(db),
+		Branches: NewBranchRepository(db),
+		Files:    NewFileRepository(db),
+		Tracks:   NewTrackRepository(db),
+	}
+}
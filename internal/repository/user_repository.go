@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"collabhub-music-backend/internal/models"
 
 	"github.com/google/uuid"
@@ -52,12 +55,61 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	return &user, nil
 }
 
+// GetByKeycloakID retrieves a user by Keycloak ID. Soft-deleted users are included so a
+// deleted account is recognized, rather than recreated, when its token is used.
+func (r *userRepository) GetByKeycloakID(keycloakID string) (*models.User, error) {
+	var user models.User
+	err := r.db.Unscoped().First(&user, "keycloak_id = ?", keycloakID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // Update updates a user in the database
 func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdateLastLogin records a login time with a single-column write that leaves updated_at untouched
+func (r *userRepository) UpdateLastLogin(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumn("last_login_at", at).Error
+}
+
 // Delete deletes a user from the database
 func (r *userRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
+
+// Restore undoes the soft delete of a user
+func (r *userRepository) Restore(id uuid.UUID) error {
+	return r.db.Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// Search finds users whose username or email contains the query (case-insensitive)
+// and returns one page of results along with the total number of matches
+func (r *userRepository) Search(query string, includeDeleted bool, limit, offset int) ([]*models.User, int64, error) {
+	db := r.db.Model(&models.User{})
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+
+	if query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		db = db.Where("username ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*models.User
+	err := db.Order("username ASC").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// escapeLike escapes the LIKE wildcard characters in a user-supplied pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
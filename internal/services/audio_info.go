@@ -0,0 +1,198 @@
+package services
+
+import (
+    "encoding/binary"
+    "io"
+    "math"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "collabhub-music-backend/internal/models"
+    "github.com/google/uuid"
+)
+
+// maxID3TagSize caps how much of an ID3 tag is read; frames past it are ignored
+const maxID3TagSize = 1 << 20
+
+// readAudioInfo returns what can be read from an audio file without a decoder.
+// The title defaults to the file name; WAV files also get their format and duration
+// from the RIFF header. BPM, key and genre come from an ID3 tag at the start of MP3
+// files or in the id3 chunk of WAV files.
+func readAudioInfo(path string) *models.AudioInfo {
+    info := &models.AudioInfo{
+        Title: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+    }
+
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".wav":
+        readWAVInfo(path, info)
+    case ".mp3":
+        readMP3Tag(path, info)
+    }
+
+    return info
+}
+
+// readMP3Tag reads the ID3v2 tag at the start of an MP3 file
+func readMP3Tag(path string, info *models.AudioInfo) {
+    file, err := os.Open(path)
+    if err != nil {
+        return
+    }
+    defer file.Close()
+
+    header := make([]byte, id3HeaderSize)
+    if _, err := io.ReadFull(file, header); err != nil || string(header[0:3]) != "ID3" {
+        return
+    }
+    size := int64(syncsafe(header[6:10]))
+    if size > maxID3TagSize {
+        size = maxID3TagSize
+    }
+    tag := make([]byte, id3HeaderSize+size)
+    copy(tag, header)
+    n, _ := io.ReadFull(file, tag[id3HeaderSize:])
+    readID3Tag(tag[:id3HeaderSize+n], info)
+}
+
+// readWAVInfo fills in sample rate, bit depth, channels, bit rate and duration from a WAV header,
+// and the tag fields from an id3 chunk. Malformed headers leave the fields untouched.
+func readWAVInfo(path string, info *models.AudioInfo) {
+    file, err := os.Open(path)
+    if err != nil {
+        return
+    }
+    defer file.Close()
+
+    header := make([]byte, 12)
+    if _, err := io.ReadFull(file, header); err != nil {
+        return
+    }
+    if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+        return
+    }
+
+    var byteRate uint32
+    chunk := make([]byte, 8)
+    for {
+        if _, err := io.ReadFull(file, chunk); err != nil {
+            return
+        }
+        id := string(chunk[0:4])
+        size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+        var consumed int64
+
+        switch id {
+        case "fmt ":
+            format := make([]byte, 16)
+            if size < 16 {
+                return
+            }
+            if _, err := io.ReadFull(file, format); err != nil {
+                return
+            }
+            info.Channels = int(binary.LittleEndian.Uint16(format[2:4]))
+            info.SampleRate = int(binary.LittleEndian.Uint32(format[4:8]))
+            byteRate = binary.LittleEndian.Uint32(format[8:12])
+            info.BitRate = int(byteRate * 8 / 1000)
+            info.BitDepth = int(binary.LittleEndian.Uint16(format[14:16]))
+            consumed = 16
+        case "data":
+            if byteRate > 0 {
+                info.Duration = float64(size) / float64(byteRate)
+            }
+        case "id3 ", "ID3 ":
+            tag := make([]byte, min(size, maxID3TagSize))
+            n, _ := io.ReadFull(file, tag)
+            readID3Tag(tag[:n], info)
+            consumed = int64(n)
+        }
+
+        // Chunks are word-aligned
+        if _, err := file.Seek(size+size%2-consumed, io.SeekCurrent); err != nil {
+            return
+        }
+    }
+}
+
+// newAudioMetadata creates the audio metadata of a file from what was read from it
+func newAudioMetadata(fileID uuid.UUID, info *models.AudioInfo) *models.AudioMetadata {
+    return &models.AudioMetadata{
+        FileID:     fileID,
+        Title:      info.Title,
+        Genre:      info.Genre,
+        BPM:        info.BPM,
+        Key:        info.Key,
+        Duration:   info.Duration,
+        BitRate:    info.BitRate,
+        SampleRate: info.SampleRate,
+        Channels:   info.Channels,
+    }
+}
+
+// refreshAudioMetadata updates stored metadata from a fresh read of its file, reporting
+// whether it changed. Title, artist and other tags may have been edited by users, so the
+// technical properties are refreshed but tag fields are only filled in when empty.
+func refreshAudioMetadata(metadata *models.AudioMetadata, info *models.AudioInfo) bool {
+    changed := false
+    if metadata.Duration != info.Duration || metadata.BitRate != info.BitRate ||
+        metadata.SampleRate != info.SampleRate || metadata.Channels != info.Channels {
+        metadata.Duration = info.Duration
+        metadata.BitRate = info.BitRate
+        metadata.SampleRate = info.SampleRate
+        metadata.Channels = info.Channels
+        changed = true
+    }
+    if metadata.BPM == 0 && info.BPM != 0 {
+        metadata.BPM = info.BPM
+        changed = true
+    }
+    if metadata.Key == "" && info.Key != "" {
+        metadata.Key = info.Key
+        changed = true
+    }
+    if metadata.Genre == "" && info.Genre != "" {
+        metadata.Genre = info.Genre
+        changed = true
+    }
+    return changed
+}
+
+// mirrorAudioMetadata copies the BPM, key, genre and duration of a file's audio metadata,
+// the source of truth for them, onto the file's track. It reports whether the track changed.
+func mirrorAudioMetadata(track *models.Track, metadata *models.AudioMetadata) bool {
+    duration := int(math.Round(metadata.Duration))
+    bpm := optional(metadata.BPM)
+    key := optional(metadata.Key)
+    genre := optional(metadata.Genre)
+
+    if track.Duration == duration && track.DurationUnknown == (metadata.Duration <= 0) &&
+        equalPtr(track.BPM, bpm) && equalPtr(track.Key, key) && equalPtr(track.Genre, genre) {
+        return false
+    }
+
+    track.Duration = duration
+    track.DurationUnknown = metadata.Duration <= 0
+    track.BPM = bpm
+    track.Key = key
+    track.Genre = genre
+    return true
+}
+
+// optional returns nil for the zero value and a pointer to v otherwise
+func optional[T comparable](v T) *T {
+    var zero T
+    if v == zero {
+        return nil
+    }
+    return &v
+}
+
+// equalPtr reports whether two optional values are both unset or hold the same value
+func equalPtr[T comparable](a, b *T) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return *a == *b
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+    "collabhub-music-backend/pkg/utils"
     "github.com/dgrijalva/jwt-go"
     "github.com/gin-gonic/gin"
     "time"
@@ -36,14 +37,14 @@ func (s *AuthService) AuthMiddleware() gin.HandlerFunc {
     return func(c *gin.Context) {
         tokenString := c.Request.Header.Get("Authorization")
         if tokenString == "" {
-            c.JSON(401, gin.H{"error": "Authorization header is required"})
+            utils.RespondError(c, 401, "Authorization header is required")
             c.Abort()
             return
         }
 
         token, err := s.ValidateToken(tokenString)
         if err != nil || !token.Valid {
-            c.JSON(401, gin.H{"error": "Invalid token"})
+            utils.RespondError(c, 401, "Invalid token")
             c.Abort()
             return
         }
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"collabhub-music-backend/internal/models"
+	"collabhub-music-backend/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommentService provides the business logic of project comments
+type CommentService struct {
+	commentRepo repository.CommentRepositoryInterface
+	projectRepo repository.ProjectRepositoryInterface
+	fileRepo    repository.FileRepositoryInterface
+	trackRepo   repository.TrackRepositoryInterface
+	policy      *PolicyService
+}
+
+// NewCommentService creates a new instance of CommentService. Without file or track
+// repositories, comments can't be left on files or tracks.
+func NewCommentService(commentRepo repository.CommentRepositoryInterface, projectRepo repository.ProjectRepositoryInterface, fileRepo repository.FileRepositoryInterface, trackRepo repository.TrackRepositoryInterface) *CommentService {
+	return &CommentService{
+		commentRepo: commentRepo,
+		projectRepo: projectRepo,
+		fileRepo:    fileRepo,
+		trackRepo:   trackRepo,
+		policy:      NewPolicyService(projectRepo, nil),
+	}
+}
+
+// CreateComment leaves a comment on a project, or on one of its tracks or files, for one of
+// its members. A reply to a reply joins the thread of the comment it answers, so threads
+// are one level deep.
+func (s *CommentService) CreateComment(userID, projectID uuid.UUID, req *models.CreateCommentRequest) (*models.Comment, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, &FieldError{Field: "body", Message: "must not be empty"}
+	}
+
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanAccessProject(userID, project); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		ProjectID: projectID,
+		TrackID:   req.TrackID,
+		FileID:    req.FileID,
+		AuthorID:  userID,
+		Body:      body,
+		Timestamp: req.Timestamp,
+	}
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(*req.ParentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if parent == nil || parent.ProjectID != projectID {
+			return nil, &FieldError{Field: "parent_id", Message: "no such comment on this project"}
+		}
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+	if req.FileID != nil {
+		if err := s.checkFile(projectID, *req.FileID); err != nil {
+			return nil, err
+		}
+	}
+	if req.TrackID != nil {
+		if err := s.checkTrack(projectID, *req.TrackID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ListComments lists a page of a project's comments and replies to one of its members,
+// oldest first
+func (s *CommentService) ListComments(userID, projectID uuid.UUID, limit, offset int) (*models.CommentPage, error) {
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanAccessProject(userID, project); err != nil {
+		return nil, err
+	}
+
+	comments, total, err := s.commentRepo.GetByProjectID(projectID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CommentPage{Comments: comments, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// DeleteComment deletes a comment and its replies. Authors can delete their own comments,
+// and the owner and admins of the project any comment on it.
+func (s *CommentService) DeleteComment(userID, commentID uuid.UUID) error {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	project, err := s.getProject(comment.ProjectID)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID == userID {
+		if err := s.policy.CanAccessProject(userID, project); err != nil {
+			return err
+		}
+	} else if err := s.policy.CanEditProject(userID, project); err != nil {
+		return err
+	}
+
+	return s.commentRepo.Delete(comment.ID)
+}
+
+// checkFile makes sure a commented file belongs to the project
+func (s *CommentService) checkFile(projectID, fileID uuid.UUID) error {
+	if s.fileRepo == nil {
+		return &FieldError{Field: "file_id", Message: "comments can't be left on files"}
+	}
+	file, err := s.fileRepo.GetByID(fileID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if file == nil || file.ProjectID != projectID {
+		return &FieldError{Field: "file_id", Message: "no such file in this project"}
+	}
+	return nil
+}
+
+// checkTrack makes sure a commented track belongs to the project
+func (s *CommentService) checkTrack(projectID, trackID uuid.UUID) error {
+	if s.trackRepo == nil {
+		return &FieldError{Field: "track_id", Message: "comments can't be left on tracks"}
+	}
+	tracks, err := s.trackRepo.GetByProjectID(projectID)
+	if err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		if track.ID == trackID {
+			return nil
+		}
+	}
+	return &FieldError{Field: "track_id", Message: "no such track in this project"}
+}
+
+// getProject loads a project, translating a missing record into ErrNotFound
+func (s *CommentService) getProject(projectID uuid.UUID) (*models.Project, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return project, err
+}
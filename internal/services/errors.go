@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Common service errors, mapped to HTTP status codes by the handlers
+var (
+	ErrNotFound  = errors.New("resource not found")
+	ErrForbidden = errors.New("insufficient permissions")
+	ErrInvalid   = errors.New("invalid request")
+	ErrConflict  = errors.New("resource is busy")
+)
+
+// FieldError reports an invalid value for a single request field.
+// It wraps ErrInvalid so callers that only check the sentinel still match.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalid
+}
+
+// denyAccess returns the error for a caller who may not perform an action on a resource.
+// Callers who can see the resource get ErrForbidden; everyone else gets ErrNotFound, so
+// responses never reveal whether a private resource exists.
+func denyAccess(visible bool) error {
+	if visible {
+		return ErrForbidden
+	}
+	return ErrNotFound
+}
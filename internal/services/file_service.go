@@ -2,35 +2,758 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"collabhub-music-backend/internal/models"
+	"collabhub-music-backend/internal/repository"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // FileService provides file-related business logic
 type FileService struct {
+	fileRepo    repository.FileRepositoryInterface
+	branchRepo  repository.BranchRepositoryInterface
+	projectRepo repository.ProjectRepositoryInterface
+	trackRepo   repository.TrackRepositoryInterface
+	policy      *PolicyService
+	extractPath string
+	layout      StorageLayout
 }
 
-// NewFileService creates a new instance of FileService
-func NewFileService() *FileService {
-	return &FileService{}
+// FileServiceConfig holds the repositories and storage settings used by the file service
+type FileServiceConfig struct {
+	Files       repository.FileRepositoryInterface
+	ExtractPath string
+	Layout      StorageLayout // arrangement of extracted projects on disk, flat by default
+
+	// Branches is needed to resync files and Projects to check project membership;
+	// the operations that need them fail without them
+	Branches repository.BranchRepositoryInterface
+	Projects repository.ProjectRepositoryInterface
+
+	// Tracks keeps tracks in sync with their file's audio metadata on reprocess
+	Tracks repository.TrackRepositoryInterface
 }
 
-// BuildFileTree builds a file tree structure for a branch
-func (s *FileService) BuildFileTree(ctx context.Context, branchID uuid.UUID) ([]models.FileTreeNode, error) {
-	// Placeholder implementation
-	tree := make([]models.FileTreeNode, 0)
-	return tree, nil
+// NewFileService creates a new instance of FileService for projects extracted with the flat layout.
+// Without branch and project repositories, files can't be resynced and versions can't be downloaded.
+func NewFileService(fileRepo repository.FileRepositoryInterface, extractPath string) *FileService {
+	return NewFileServiceWithConfig(FileServiceConfig{
+		Files:       fileRepo,
+		ExtractPath: extractPath,
+	})
+}
+
+// NewFileServiceWithConfig creates a new instance of FileService
+func NewFileServiceWithConfig(cfg FileServiceConfig) *FileService {
+	if cfg.Layout == "" {
+		cfg.Layout = StorageLayoutFlat
+	}
+
+	var policy *PolicyService
+	if cfg.Projects != nil {
+		policy = NewPolicyService(cfg.Projects, nil)
+	}
+
+	return &FileService{
+		fileRepo:    cfg.Files,
+		branchRepo:  cfg.Branches,
+		projectRepo: cfg.Projects,
+		trackRepo:   cfg.Tracks,
+		policy:      policy,
+		extractPath: cfg.ExtractPath,
+		layout:      cfg.Layout,
+	}
+}
+
+// BuildFileTree builds the folder tree of a project branch's files, the default branch when
+// branchID is nil. With audioOnly, files that aren't audio are left out along with the folders
+// that hold no audio. Only members of the project can read it.
+func (s *FileService) BuildFileTree(ctx context.Context, userID, projectID, branchID uuid.UUID, audioOnly bool) ([]models.FileTreeNode, error) {
+	if s.projectRepo == nil || s.branchRepo == nil {
+		return nil, errors.New("file service has no project or branch repository")
+	}
+
+	project, err := s.projectRepo.GetByID(projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanAccessProject(userID, project); err != nil {
+		return nil, err
+	}
+
+	branch, err := s.projectBranch(projectID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.fileRepo.GetByBranchID(branch.ID)
+	if err != nil {
+		return nil, err
+	}
+	return buildFileTree(files, audioOnly), nil
 }
 
 // GetFileByID retrieves a file by ID
 func (s *FileService) GetFileByID(ctx context.Context, fileID uuid.UUID) (*models.File, error) {
-	return nil, fmt.Errorf("not implemented")
+	file, err := s.fileRepo.GetByID(fileID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// OpenFileContent returns a file record together with its content opened for reading, for
+// members of the file's project. Unknown files, and files of private projects the user
+// isn't a member of, are reported as ErrNotFound. The caller must close the returned content.
+func (s *FileService) OpenFileContent(ctx context.Context, userID, fileID uuid.UUID) (*models.File, *os.File, error) {
+	file, err := s.getAccessibleFile(ctx, userID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := os.Open(file.StoragePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	return file, content, nil
+}
+
+// OpenVersionContent returns a stored version of a file together with its content opened for
+// reading, for members of the file's project. Unknown files and versions, and files of
+// private projects the user isn't a member of, are reported as ErrNotFound.
+// The caller must close the returned content.
+func (s *FileService) OpenVersionContent(ctx context.Context, userID, fileID uuid.UUID, version int) (*models.File, *models.FileVersion, *os.File, error) {
+	file, err := s.getAccessibleFile(ctx, userID, fileID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	fileVersion, err := s.getVersion(fileID, version)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	content, err := os.Open(fileVersion.StoragePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil, ErrNotFound
+		}
+		return nil, nil, nil, err
+	}
+
+	return file, fileVersion, content, nil
+}
+
+// GetFileMetadata returns the audio metadata of a file for members of the file's project.
+// Unknown files, and files of private projects the user isn't a member of, are reported
+// as ErrNotFound.
+func (s *FileService) GetFileMetadata(ctx context.Context, userID, fileID uuid.UUID) (*models.FileMetadata, error) {
+	file, err := s.getAccessibleFile(ctx, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.FileMetadata{
+		FileID:      file.ID,
+		HasMetadata: file.AudioMetadata != nil,
+		Metadata:    file.AudioMetadata,
+	}, nil
+}
+
+// BulkUpdateMetadata applies a metadata patch to the audio files at the given paths of a
+// project's default branch, in one transaction. Files without metadata get it. Paths that
+// aren't audio files of the branch are skipped. Members other than viewers may tag files.
+func (s *FileService) BulkUpdateMetadata(ctx context.Context, userID, projectID uuid.UUID, req *models.BulkMetadataRequest) (*models.BulkMetadataResult, error) {
+	if err := validateMetadataPatch(req.Metadata); err != nil {
+		return nil, err
+	}
+	if len(req.Paths) > MaxMetadataBatchSize {
+		return nil, &FieldError{Field: "paths", Message: fmt.Sprintf("at most %d files can be tagged at once", MaxMetadataBatchSize)}
+	}
+	if s.projectRepo == nil || s.branchRepo == nil {
+		return nil, errors.New("file service has no project or branch repository")
+	}
+
+	if _, err := s.getEditableProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	branch, err := s.defaultBranch(projectID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.fileRepo.GetByBranchID(branch.ID)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*models.File, len(files))
+	for _, file := range files {
+		byPath[filepath.ToSlash(file.Path)] = file
+	}
+
+	result := &models.BulkMetadataResult{Skipped: []string{}}
+	var created, updated []*models.AudioMetadata
+	seen := make(map[string]bool, len(req.Paths))
+	for _, path := range req.Paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		file, ok := byPath[path]
+		if !ok || file.FileType != "audio" {
+			result.Skipped = append(result.Skipped, path)
+			continue
+		}
+		if file.AudioMetadata == nil {
+			metadata := &models.AudioMetadata{FileID: file.ID}
+			req.Metadata.Apply(metadata)
+			created = append(created, metadata)
+		} else {
+			req.Metadata.Apply(file.AudioMetadata)
+			updated = append(updated, file.AudioMetadata)
+		}
+	}
+
+	if len(created) > 0 || len(updated) > 0 {
+		if err := s.fileRepo.SaveAudioMetadataBatch(created, updated); err != nil {
+			return nil, err
+		}
+	}
+	result.Updated = len(created) + len(updated)
+	return result, nil
+}
+
+// validateMetadataPatch checks that a patch sets something and that its year and track
+// number are plausible
+func validateMetadataPatch(patch models.AudioMetadataPatch) error {
+	if patch.IsEmpty() {
+		return &FieldError{Field: "metadata", Message: "must set at least one field"}
+	}
+	if maxYear := time.Now().Year() + 1; patch.Year != 0 && (patch.Year < 1800 || patch.Year > maxYear) {
+		return &FieldError{Field: "year", Message: fmt.Sprintf("must be between 1800 and %d", maxYear)}
+	}
+	if patch.Track < 0 || patch.Track > 999 {
+		return &FieldError{Field: "track", Message: "must be between 1 and 999"}
+	}
+	return nil
+}
+
+// getEditableProject loads a project if the user may change its files
+func (s *FileService) getEditableProject(userID, projectID uuid.UUID) (*models.Project, error) {
+	if s.projectRepo == nil {
+		return nil, errors.New("file service has no project repository")
+	}
+
+	project, err := s.projectRepo.GetByID(projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanEditFiles(userID, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// getAccessibleFile loads a file, with its audio metadata, if the user may access its project
+func (s *FileService) getAccessibleFile(ctx context.Context, userID, fileID uuid.UUID) (*models.File, error) {
+	if s.projectRepo == nil {
+		return nil, errors.New("file service has no project repository")
+	}
+
+	file, err := s.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.GetByID(file.ProjectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanAccessProject(userID, project); err != nil {
+		return nil, err
+	}
+	return file, nil
 }
 
-// DeleteFile deletes a file
+// ErrChecksumMismatch is returned when a stored file's content no longer matches its checksum
+var ErrChecksumMismatch = errors.New("file content does not match its checksum")
+
+// VerifyContent hashes the content and compares it with the file's stored SHA-256 checksum,
+// returning ErrChecksumMismatch if they differ. Files stored without a checksum can't be
+// verified and always pass. The content is rewound to its start afterwards.
+func (s *FileService) VerifyContent(file *models.File, content io.ReadSeeker) error {
+	if file.Checksum == "" {
+		return nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(actual, file.Checksum) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, file.Checksum, actual)
+	}
+	return nil
+}
+
+// DeleteFile soft-deletes a file
 func (s *FileService) DeleteFile(ctx context.Context, fileID uuid.UUID) error {
-	return fmt.Errorf("not implemented")
+	err := s.fileRepo.Delete(fileID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// DiffVersions compares two versions of a file by size, checksum and version metadata, for
+// members of the file's project. For audio files it also compares the duration, format,
+// tempo and key read from each version's content. It returns ErrNotFound when the file or either version
+// does not exist, or the file belongs to a private project the user isn't a member of.
+func (s *FileService) DiffVersions(ctx context.Context, userID, fileID uuid.UUID, from, to int) (*models.FileVersionDiff, error) {
+	if _, err := s.getAccessibleFile(ctx, userID, fileID); err != nil {
+		return nil, err
+	}
+
+	fromVersion, err := s.getVersion(fileID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.getVersion(fileID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.FileVersionDiff{
+		FileID:        fileID,
+		FromVersion:   from,
+		ToVersion:     to,
+		SizeFrom:      fromVersion.Size,
+		SizeTo:        toVersion.Size,
+		SizeDelta:     toVersion.Size - fromVersion.Size,
+		ChecksumEqual: fromVersion.Checksum == toVersion.Checksum,
+		Changes:       []models.FieldChange{},
+	}
+
+	if fromVersion.Size != toVersion.Size {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "size", From: fromVersion.Size, To: toVersion.Size})
+	}
+	if fromVersion.Checksum != toVersion.Checksum {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "checksum", From: fromVersion.Checksum, To: toVersion.Checksum})
+	}
+	if fromVersion.Comment != toVersion.Comment {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "comment", From: fromVersion.Comment, To: toVersion.Comment})
+	}
+	if fromVersion.CreatedBy != toVersion.CreatedBy {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "created_by", From: fromVersion.CreatedBy, To: toVersion.CreatedBy})
+	}
+	diff.Changes = append(diff.Changes, diffAudioInfo(fromVersion, toVersion)...)
+
+	return diff, nil
+}
+
+// diffAudioInfo lists the audio metadata fields that differ between the content of two
+// versions. Versions that aren't audio files or whose content is missing have none.
+func diffAudioInfo(fromVersion, toVersion *models.FileVersion) []models.FieldChange {
+	if !isAudioVersion(fromVersion) || !isAudioVersion(toVersion) {
+		return nil
+	}
+	from, to := readAudioInfo(fromVersion.StoragePath), readAudioInfo(toVersion.StoragePath)
+
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"duration", from.Duration, to.Duration},
+		{"sample_rate", from.SampleRate, to.SampleRate},
+		{"bit_depth", from.BitDepth, to.BitDepth},
+		{"bit_rate", from.BitRate, to.BitRate},
+		{"channels", from.Channels, to.Channels},
+		{"bpm", from.BPM, to.BPM},
+		{"key", from.Key, to.Key},
+	}
+
+	var changes []models.FieldChange
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, models.FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	return changes
+}
+
+// isAudioVersion reports whether a version's content is a supported audio file on disk
+func isAudioVersion(version *models.FileVersion) bool {
+	if !audioExtensions[strings.ToLower(filepath.Ext(version.StoragePath))] {
+		return false
+	}
+	_, err := os.Stat(version.StoragePath)
+	return err == nil
+}
+
+// getVersion loads a file version, translating a missing record into ErrNotFound
+func (s *FileService) getVersion(fileID uuid.UUID, version int) (*models.FileVersion, error) {
+	fileVersion, err := s.fileRepo.GetVersion(fileID, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return fileVersion, err
+}
+
+// ReprocessProject re-reads every extracted file of a project, recomputing checksums and
+// audio metadata, and updates the stored File and AudioMetadata rows that differ. Tracks
+// of the files are brought in line with the refreshed metadata. Files on disk without a
+// File row get one on the default branch, uploaded by userID, with their audio metadata.
+// Running it twice in a row changes nothing the second time. Members other than viewers
+// may reprocess a project.
+func (s *FileService) ReprocessProject(userID, projectID uuid.UUID) (*models.ReprocessResult, error) {
+	if s.branchRepo == nil {
+		return nil, errors.New("file service has no branch repository")
+	}
+	if _, err := s.getEditableProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	projectPath := s.layout.ProjectDir(s.extractPath, projectID)
+	if _, err := os.Stat(projectPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	files, err := s.fileRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*models.File, len(files))
+	for _, file := range files {
+		byPath[filepath.ToSlash(file.Path)] = file
+	}
+
+	tracksByFile := make(map[uuid.UUID]*models.Track)
+	if s.trackRepo != nil {
+		tracks, err := s.trackRepo.GetByProjectID(projectID)
+		if err != nil {
+			return nil, err
+		}
+		for _, track := range tracks {
+			if track.FileID != nil {
+				tracksByFile[*track.FileID] = track
+			}
+		}
+	}
+
+	result := &models.ReprocessResult{
+		ProjectID: projectID,
+		Updated:   []string{},
+		Created:   []string{},
+	}
+
+	var branch *models.Branch
+	var created []*models.File
+
+	err = filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		result.Scanned++
+
+		file, ok := byPath[relPath]
+		if !ok {
+			if branch == nil {
+				if branch, err = s.defaultBranch(projectID); err != nil {
+					return err
+				}
+			}
+			file, err := newDiskFile(projectID, branch.ID, userID, relPath, path, info)
+			if err != nil {
+				return err
+			}
+			created = append(created, file)
+			result.Created = append(result.Created, relPath)
+			return nil
+		}
+
+		changed, err := s.reprocessFile(file, tracksByFile[file.ID], path, info.Size())
+		if err != nil {
+			return err
+		}
+		if changed {
+			result.Updated = append(result.Updated, relPath)
+		} else {
+			result.Unchanged++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(created) > 0 {
+		if err := s.fileRepo.SaveBatch(created, nil); err != nil {
+			return nil, err
+		}
+		for _, file := range created {
+			if file.FileType != "audio" {
+				continue
+			}
+			if err := s.fileRepo.CreateAudioMetadata(newAudioMetadata(file.ID, readAudioInfo(file.StoragePath))); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ResyncProjectFiles reconciles the File rows of a project's default branch with its
+// extracted files: files on disk without a row get one, uploaded by userID, and rows whose
+// file is gone from disk are soft-deleted. All changes are written in one transaction.
+// Members other than viewers may resync a project.
+func (s *FileService) ResyncProjectFiles(userID, projectID uuid.UUID) (*models.ResyncResult, error) {
+	if s.branchRepo == nil {
+		return nil, errors.New("file service has no branch repository")
+	}
+	if _, err := s.getEditableProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	branch, err := s.defaultBranch(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectPath := s.layout.ProjectDir(s.extractPath, projectID)
+	if _, err := os.Stat(projectPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	files, err := s.fileRepo.GetByBranchID(branch.ID)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*models.File, len(files))
+	for _, file := range files {
+		byPath[filepath.ToSlash(file.Path)] = file
+	}
+
+	result := &models.ResyncResult{
+		ProjectID: projectID,
+		BranchID:  branch.ID,
+		Created:   []string{},
+		Deleted:   []string{},
+	}
+
+	var created []*models.File
+	err = filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if _, ok := byPath[relPath]; ok {
+			delete(byPath, relPath)
+			result.Unchanged++
+			return nil
+		}
+
+		file, err := newDiskFile(projectID, branch.ID, userID, relPath, path, info)
+		if err != nil {
+			return err
+		}
+		created = append(created, file)
+		result.Created = append(result.Created, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Whatever is left in byPath has no file on disk any more
+	deletedIDs := make([]uuid.UUID, 0, len(byPath))
+	for relPath, file := range byPath {
+		deletedIDs = append(deletedIDs, file.ID)
+		result.Deleted = append(result.Deleted, relPath)
+	}
+	sort.Strings(result.Deleted)
+
+	if len(created) > 0 || len(deletedIDs) > 0 {
+		if err := s.fileRepo.SyncBatch(created, deletedIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// newDiskFile builds the File row of an extracted file found on disk at path
+func newDiskFile(projectID, branchID, userID uuid.UUID, relPath, path string, info os.FileInfo) (*models.File, error) {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	return &models.File{
+		ID:           uuid.New(),
+		ProjectID:    projectID,
+		BranchID:     branchID,
+		Name:         info.Name(),
+		OriginalName: info.Name(),
+		Path:         relPath,
+		FileType:     fileTypeOf(ext),
+		MimeType:     mime.TypeByExtension(ext),
+		Size:         info.Size(),
+		Checksum:     checksum,
+		StoragePath:  path,
+		UploadedBy:   userID,
+	}, nil
+}
+
+// defaultBranch returns the default branch of a project, or ErrNotFound if it has none
+func (s *FileService) defaultBranch(projectID uuid.UUID) (*models.Branch, error) {
+	branches, err := s.branchRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		if branch.IsDefault {
+			return branch, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// projectBranch returns the project's branch with the given ID, or its default branch when
+// the ID is nil. Branches of other projects are reported as ErrNotFound.
+func (s *FileService) projectBranch(projectID, branchID uuid.UUID) (*models.Branch, error) {
+	if branchID == uuid.Nil {
+		return s.defaultBranch(projectID)
+	}
+	branches, err := s.branchRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		if branch.ID == branchID {
+			return branch, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// fileTypeOf classifies a file as audio, image, video or other from its extension
+func fileTypeOf(ext string) string {
+	if audioExtensions[ext] {
+		return "audio"
+	}
+	mimeType := mime.TypeByExtension(ext)
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	default:
+		return "other"
+	}
+}
+
+// reprocessFile refreshes a single file row, its audio metadata and the track mirroring
+// it from disk, reporting whether anything was written. track may be nil.
+func (s *FileService) reprocessFile(file *models.File, track *models.Track, path string, size int64) (bool, error) {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return false, err
+	}
+
+	// Metadata is written separately so saving the file doesn't touch the association
+	metadata := file.AudioMetadata
+	file.AudioMetadata = nil
+
+	changed := false
+	if file.Checksum != checksum || file.Size != size {
+		file.Checksum = checksum
+		file.Size = size
+		if err := s.fileRepo.Update(file); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+		return changed, nil
+	}
+
+	info := readAudioInfo(path)
+	if metadata == nil {
+		metadata = newAudioMetadata(file.ID, info)
+		if err := s.fileRepo.CreateAudioMetadata(metadata); err != nil {
+			return false, err
+		}
+		changed = true
+	} else if refreshAudioMetadata(metadata, info) {
+		if err := s.fileRepo.UpdateAudioMetadata(metadata); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if track != nil && mirrorAudioMetadata(track, metadata) {
+		if err := s.trackRepo.Update(track); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	return changed, nil
 }
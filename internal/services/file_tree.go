@@ -0,0 +1,105 @@
+package services
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"collabhub-music-backend/internal/models"
+)
+
+// fileTreeFolder gathers the subfolders and files of one folder while a tree is built
+type fileTreeFolder struct {
+	folders map[string]*fileTreeFolder
+	files   []*models.File
+}
+
+func newFileTreeFolder() *fileTreeFolder {
+	return &fileTreeFolder{folders: make(map[string]*fileTreeFolder)}
+}
+
+// buildFileTree arranges files into folders following their relative paths. Folders only
+// exist to hold files, so with audioOnly a folder without audio files is dropped entirely.
+func buildFileTree(files []*models.File, audioOnly bool) []models.FileTreeNode {
+	root := newFileTreeFolder()
+	for _, file := range files {
+		if audioOnly && file.FileType != string(models.FileTypeAudio) {
+			continue
+		}
+
+		folder := root
+		dir := path.Dir(strings.Trim(filepath.ToSlash(file.Path), "/"))
+		if dir != "." {
+			for _, name := range strings.Split(dir, "/") {
+				child, ok := folder.folders[name]
+				if !ok {
+					child = newFileTreeFolder()
+					folder.folders[name] = child
+				}
+				folder = child
+			}
+		}
+		folder.files = append(folder.files, file)
+	}
+
+	nodes, _ := root.nodes("")
+	return nodes
+}
+
+// nodes returns the folder's subfolders followed by its files, each sorted by name, together
+// with the total size of the files beneath it
+func (f *fileTreeFolder) nodes(prefix string) ([]models.FileTreeNode, int64) {
+	nodes := make([]models.FileTreeNode, 0, len(f.folders)+len(f.files))
+	var total int64
+
+	names := make([]string, 0, len(f.folders))
+	for name := range f.folders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		folderPath := prefix + name
+		children, size := f.folders[name].nodes(folderPath + "/")
+		nodes = append(nodes, models.FileTreeNode{
+			ID:       folderPath,
+			Name:     name,
+			Type:     "folder",
+			Path:     folderPath,
+			Size:     &size,
+			Children: children,
+		})
+		total += size
+	}
+
+	sort.Slice(f.files, func(i, j int) bool {
+		return path.Base(filepath.ToSlash(f.files[i].Path)) < path.Base(filepath.ToSlash(f.files[j].Path))
+	})
+	for _, file := range f.files {
+		filePath := filepath.ToSlash(file.Path)
+		size := file.Size
+		nodes = append(nodes, models.FileTreeNode{
+			ID:   file.ID.String(),
+			Name: path.Base(filePath),
+			Type: "file",
+			Path: filePath,
+			Size: &size,
+			File: &models.ProjectFile{
+				ID:            file.ID,
+				ProjectID:     file.ProjectID,
+				BranchID:      file.BranchID,
+				Name:          file.Name,
+				Path:          filePath,
+				Type:          models.FileType(file.FileType),
+				Size:          file.Size,
+				MimeType:      file.MimeType,
+				Checksum:      file.Checksum,
+				UploadedAt:    file.CreatedAt,
+				UpdatedAt:     file.UpdatedAt,
+				AudioMetadata: file.AudioMetadata,
+			},
+		})
+		total += size
+	}
+	return nodes, total
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+    "bytes"
+    "encoding/binary"
+    "math"
+    "strconv"
+    "strings"
+    "unicode/utf16"
+
+    "collabhub-music-backend/internal/models"
+)
+
+// id3HeaderSize is the size of an ID3v2 tag header and of a v2.3/v2.4 frame header
+const id3HeaderSize = 10
+
+// readID3Tag fills in the BPM, key and genre found in an ID3v2.3 or v2.4 tag. Tags of
+// other versions, unsynchronised tags and malformed frames leave the fields untouched.
+func readID3Tag(tag []byte, info *models.AudioInfo) {
+    if len(tag) < id3HeaderSize || string(tag[0:3]) != "ID3" {
+        return
+    }
+    version, flags := tag[3], tag[5]
+    if (version != 3 && version != 4) || flags&0x80 != 0 {
+        return
+    }
+
+    size := int(syncsafe(tag[6:10]))
+    if size > len(tag)-id3HeaderSize {
+        size = len(tag) - id3HeaderSize
+    }
+    frames := tag[id3HeaderSize : id3HeaderSize+size]
+
+    // Skip the extended header; v2.3 counts its size without the size field itself
+    if flags&0x40 != 0 {
+        if len(frames) < 4 {
+            return
+        }
+        extended := int(binary.BigEndian.Uint32(frames[0:4])) + 4
+        if version == 4 {
+            extended = int(syncsafe(frames[0:4]))
+        }
+        if extended > len(frames) {
+            return
+        }
+        frames = frames[extended:]
+    }
+
+    for len(frames) >= id3HeaderSize && frames[0] != 0 {
+        id := string(frames[0:4])
+        frameSize := int(binary.BigEndian.Uint32(frames[4:8]))
+        if version == 4 {
+            frameSize = int(syncsafe(frames[4:8]))
+        }
+        if frameSize > len(frames)-id3HeaderSize {
+            return
+        }
+        body := frames[id3HeaderSize : id3HeaderSize+frameSize]
+        frames = frames[id3HeaderSize+frameSize:]
+
+        switch id {
+        case "TBPM":
+            if bpm, err := strconv.ParseFloat(id3Text(body), 64); err == nil && bpm > 0 {
+                info.BPM = int(math.Round(bpm))
+            }
+        case "TKEY":
+            info.Key = id3Text(body)
+        case "TCON":
+            info.Genre = id3Text(body)
+        }
+    }
+}
+
+// syncsafe decodes a 28-bit ID3 integer stored 7 bits per byte
+func syncsafe(b []byte) uint32 {
+    return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
+
+// id3Text decodes the first string of a text frame body
+func id3Text(body []byte) string {
+    if len(body) == 0 {
+        return ""
+    }
+    encoding, text := body[0], body[1:]
+
+    var value string
+    switch encoding {
+    case 0: // ISO-8859-1
+        if i := bytes.IndexByte(text, 0); i >= 0 {
+            text = text[:i]
+        }
+        runes := make([]rune, len(text))
+        for i, b := range text {
+            runes[i] = rune(b)
+        }
+        value = string(runes)
+    case 1, 2: // UTF-16 with a byte order mark, UTF-16BE
+        order := binary.ByteOrder(binary.BigEndian)
+        if encoding == 1 && len(text) >= 2 {
+            if text[0] == 0xff && text[1] == 0xfe {
+                order = binary.LittleEndian
+            }
+            text = text[2:]
+        }
+        units := make([]uint16, 0, len(text)/2)
+        for i := 0; i+1 < len(text); i += 2 {
+            unit := order.Uint16(text[i : i+2])
+            if unit == 0 {
+                break
+            }
+            units = append(units, unit)
+        }
+        value = string(utf16.Decode(units))
+    case 3: // UTF-8
+        if i := bytes.IndexByte(text, 0); i >= 0 {
+            text = text[:i]
+        }
+        value = string(text)
+    }
+    return strings.TrimSpace(value)
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"collabhub-music-backend/internal/models"
+	"collabhub-music-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultJobTTL is how long finished jobs stay listed when no TTL is configured
+const DefaultJobTTL = 24 * time.Hour
+
+// JobManager runs ZIP extractions in the background and keeps their state in memory.
+// Jobs are lost on restart; the extracted files are not.
+//
+// The extraction goroutines, request handlers and the janitor share the job map, so every
+// access goes through mu, and jobs leave the manager only as copies taken under the lock.
+type JobManager struct {
+	zipService *ZipService
+	webhooks   *WebhookSender
+	ttl        time.Duration
+
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]*jobEntry
+}
+
+// JobManagerConfig configures a JobManager
+type JobManagerConfig struct {
+	ZipService *ZipService
+	Webhooks   *WebhookSender // nil to send no extraction events
+
+	// JobTTL is how long a finished job stays listed before EvictExpired drops it
+	JobTTL time.Duration
+}
+
+// jobEntry is a job together with what is needed to stop it and the channels of those
+// watching it
+type jobEntry struct {
+	job      *models.ExtractionJob
+	cancel   context.CancelFunc
+	done     chan struct{}
+	watchers map[chan models.ExtractionJob]struct{}
+}
+
+// NewJobManager creates a new instance of JobManager; webhooks may be nil to send no
+// extraction events
+func NewJobManager(zipService *ZipService, webhooks *WebhookSender) *JobManager {
+	return NewJobManagerWithConfig(JobManagerConfig{
+		ZipService: zipService,
+		Webhooks:   webhooks,
+	})
+}
+
+// NewJobManagerWithConfig creates a new JobManager; a zero TTL falls back to DefaultJobTTL
+func NewJobManagerWithConfig(cfg JobManagerConfig) *JobManager {
+	if cfg.JobTTL <= 0 {
+		cfg.JobTTL = DefaultJobTTL
+	}
+
+	return &JobManager{
+		zipService: cfg.ZipService,
+		webhooks:   cfg.Webhooks,
+		ttl:        cfg.JobTTL,
+		jobs:       make(map[uuid.UUID]*jobEntry),
+	}
+}
+
+// StartExtraction queues the extraction of an uploaded archive into a project and returns
+// the new job. The extraction waits for a slot like any other. Extracted files are saved as
+// uploaded by userID unless opts names someone else.
+func (m *JobManager) StartExtraction(userID, fileID uuid.UUID, zipPath string, projectID uuid.UUID, opts ExtractOptions) *models.ExtractionJob {
+	job := &models.ExtractionJob{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FileID:    fileID,
+		ProjectID: projectID,
+		Status:    models.JobStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if opts.UploadedBy == uuid.Nil {
+		opts.UploadedBy = userID
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	entry := &jobEntry{job: job, cancel: cancel, done: make(chan struct{}), watchers: make(map[chan models.ExtractionJob]struct{})}
+	m.mu.Lock()
+	m.jobs[job.ID] = entry
+	snapshot := *job
+	m.mu.Unlock()
+
+	go m.runExtraction(ctx, entry, zipPath, opts)
+	return &snapshot
+}
+
+// runExtraction extracts the archive for a job and records the outcome
+func (m *JobManager) runExtraction(ctx context.Context, entry *jobEntry, zipPath string, opts ExtractOptions) {
+	jobID := entry.job.ID
+	projectID := m.update(entry, func(job *models.ExtractionJob) {
+		job.Status = models.JobStatusRunning
+	}).ProjectID
+	opts.Progress = func(processed, total int) {
+		m.update(entry, func(job *models.ExtractionJob) {
+			job.Processed = processed
+			job.Total = total
+		})
+	}
+
+	result, err := m.zipService.ExtractZipWithOptions(ctx, zipPath, projectID, opts)
+	if err == nil && !result.Success {
+		err = errors.New(result.Error)
+	}
+	canceled := err != nil && ctx.Err() != nil
+
+	finished := m.update(entry, func(job *models.ExtractionJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		switch {
+		case canceled:
+			job.Status = models.JobStatusCanceled
+		case err != nil:
+			job.Status = models.JobStatusFailed
+			job.Error = err.Error()
+		default:
+			job.Status = models.JobStatusCompleted
+			job.Summary = &models.ExtractionJobSummary{
+				TotalFiles:   result.TotalFiles,
+				AudioFiles:   len(result.AudioFiles),
+				SkippedFiles: len(result.SkippedFiles),
+				TotalSize:    result.TotalSize,
+			}
+		}
+	})
+
+	entry.cancel()
+	close(entry.done)
+
+	if err != nil && !canceled {
+		logger.WithFields(logrus.Fields{
+			"job_id":     jobID,
+			"project_id": projectID,
+		}).Errorf("Extraction job failed: %v", err)
+	}
+	if err == nil {
+		if markErr := m.zipService.MarkExtracted(finished.FileID, projectID); markErr != nil {
+			logger.WithFields(logrus.Fields{
+				"job_id":  jobID,
+				"file_id": finished.FileID,
+			}).Warnf("Failed to mark upload as extracted: %v", markErr)
+		}
+	}
+	if !canceled {
+		m.sendCompleted(finished)
+	}
+}
+
+// sendCompleted posts the extraction.completed webhook event for a finished job
+func (m *JobManager) sendCompleted(job models.ExtractionJob) {
+	if m.webhooks == nil {
+		return
+	}
+
+	data := ExtractionCompletedData{
+		JobID:   job.ID,
+		Success: job.Status == models.JobStatusCompleted,
+		Error:   job.Error,
+	}
+	if job.Summary != nil {
+		data.TotalFiles = job.Summary.TotalFiles
+		data.AudioFiles = job.Summary.AudioFiles
+		data.SkippedFiles = job.Summary.SkippedFiles
+	}
+
+	err := m.webhooks.Send(WebhookEvent{
+		Event:      WebhookEventExtractionCompleted,
+		ProjectID:  job.ProjectID,
+		OccurredAt: *job.FinishedAt,
+		Data:       data,
+	})
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"job_id":     job.ID,
+			"project_id": job.ProjectID,
+		}).Errorf("Failed to send extraction webhook: %v", err)
+	}
+}
+
+// update applies change to a job under the lock, passes a copy of the result to the job's
+// watchers and returns it. Watchers are let go once the job finishes.
+func (m *JobManager) update(entry *jobEntry, change func(job *models.ExtractionJob)) models.ExtractionJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	change(entry.job)
+	snapshot := *entry.job
+	for watcher := range entry.watchers {
+		sendLatest(watcher, snapshot)
+		if snapshot.Status.Finished() {
+			close(watcher)
+			delete(entry.watchers, watcher)
+		}
+	}
+	return snapshot
+}
+
+// sendLatest queues a job update for a watcher without waiting for it. A watcher that falls
+// behind loses its oldest queued update, so the latest one, and the final one, always arrive.
+// It must be called with mu held, which makes it the channel's only sender.
+func sendLatest(watcher chan models.ExtractionJob, job models.ExtractionJob) {
+	select {
+	case watcher <- job:
+		return
+	default:
+	}
+	select {
+	case <-watcher:
+	default:
+	}
+	watcher <- job
+}
+
+// WatchJob returns a copy of one of the user's jobs and a channel that receives a copy after
+// every change until the job finishes; the channel is closed after the final one. stop must
+// be called once the caller is no longer reading. Jobs of other users are ErrNotFound.
+func (m *JobManager) WatchJob(userID, jobID uuid.UUID) (job models.ExtractionJob, updates <-chan models.ExtractionJob, stop func(), err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.jobs[jobID]
+	if !ok || entry.job.UserID != userID {
+		return models.ExtractionJob{}, nil, nil, ErrNotFound
+	}
+
+	watcher := make(chan models.ExtractionJob, 8)
+	if entry.job.Status.Finished() {
+		close(watcher)
+		return *entry.job, watcher, func() {}, nil
+	}
+	entry.watchers[watcher] = struct{}{}
+
+	stop = func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, watching := entry.watchers[watcher]; watching {
+			close(watcher)
+			delete(entry.watchers, watcher)
+		}
+	}
+	return *entry.job, watcher, stop, nil
+}
+
+// ListJobs returns the user's jobs, newest first. An empty status matches every job.
+func (m *JobManager) ListJobs(userID uuid.UUID, status models.JobStatus) []*models.ExtractionJob {
+	m.mu.RLock()
+	jobs := []*models.ExtractionJob{}
+	for _, entry := range m.jobs {
+		job := entry.job
+		if job.UserID != userID || (status != "" && job.Status != status) {
+			continue
+		}
+		snapshot := *job
+		jobs = append(jobs, &snapshot)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// CancelJob stops a pending or running job of the user and waits until its partially
+// extracted files are removed. Jobs of other users are ErrNotFound and jobs that have
+// already finished are ErrConflict.
+func (m *JobManager) CancelJob(ctx context.Context, userID, jobID uuid.UUID) (*models.ExtractionJob, error) {
+	m.mu.RLock()
+	entry, ok := m.jobs[jobID]
+	var owner uuid.UUID
+	var status models.JobStatus
+	if ok {
+		owner = entry.job.UserID
+		status = entry.job.Status
+	}
+	m.mu.RUnlock()
+
+	if !ok || owner != userID {
+		return nil, ErrNotFound
+	}
+	if status.Finished() {
+		return nil, fmt.Errorf("%w: job has already %s", ErrConflict, status)
+	}
+
+	entry.cancel()
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mu.RLock()
+	snapshot := *entry.job
+	m.mu.RUnlock()
+	return &snapshot, nil
+}
+
+// EvictExpired drops the jobs that finished more than the TTL ago and returns how many
+// were dropped. Pending and running jobs are never evicted.
+func (m *JobManager) EvictExpired() int {
+	cutoff := time.Now().Add(-m.ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	evicted := 0
+	for id, entry := range m.jobs {
+		if entry.job.FinishedAt != nil && entry.job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// RunJanitor evicts expired jobs every interval until ctx is done
+func (m *JobManager) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if evicted := m.EvictExpired(); evicted > 0 {
+				logger.WithFields(logrus.Fields{"evicted": evicted}).Debug("Evicted expired jobs")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "net/http"
     "strings"
@@ -35,11 +36,22 @@ type KeycloakUser struct {
     ID          string                 `json:"id,omitempty"`
     Username    string                 `json:"username"`
     Email       string                 `json:"email"`
+    EmailVerified bool                 `json:"emailVerified"`
     FirstName   string                 `json:"firstName"`
     LastName    string                 `json:"lastName"`
     Enabled     bool                   `json:"enabled"`
     Attributes  map[string][]string    `json:"attributes,omitempty"`
     Credentials []KeycloakCredential   `json:"credentials,omitempty"`
+    RequiredActions []string           `json:"requiredActions,omitempty"`
+}
+
+type userInfoClaims struct {
+    Subject           string `json:"sub"`
+    PreferredUsername string `json:"preferred_username"`
+    Email             string `json:"email"`
+    EmailVerified     bool   `json:"email_verified"`
+    GivenName         string `json:"given_name"`
+    FamilyName        string `json:"family_name"`
 }
 
 type KeycloakCredential struct {
@@ -80,7 +92,7 @@ func (k *KeycloakService) getAdminToken(ctx context.Context) (string, error) {
         return k.adminToken, nil
     }
 
-    tokenURL := fmt.Sprintf("%s/realms/%s/protocol/openid_connect/token", k.baseURL, k.realm)
+    tokenURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", k.baseURL, k.realm)
     
     resp, err := k.client.R().
         SetContext(ctx).
@@ -111,12 +123,52 @@ func (k *KeycloakService) getAdminToken(ctx context.Context) (string, error) {
     return k.adminToken, nil
 }
 
+// ErrInvalidCredentials is returned when Keycloak refuses a username and password
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Login exchanges a user's username, or email, and password for tokens
+func (k *KeycloakService) Login(ctx context.Context, username, password string) (*TokenResponse, error) {
+    tokenURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", k.baseURL, k.realm)
+
+    resp, err := k.client.R().
+        SetContext(ctx).
+        SetHeader("Content-Type", "application/x-www-form-urlencoded").
+        SetFormData(map[string]string{
+            "grant_type":    "password",
+            "client_id":     k.clientID,
+            "client_secret": k.clientSecret,
+            "username":      username,
+            "password":      password,
+        }).
+        Post(tokenURL)
+
+    if err != nil {
+        return nil, fmt.Errorf("failed to log in: %w", err)
+    }
+
+    switch resp.StatusCode() {
+    case http.StatusOK:
+        // Continue processing
+    case http.StatusBadRequest, http.StatusUnauthorized:
+        // Keycloak answers wrong passwords and disabled accounts with invalid_grant
+        return nil, ErrInvalidCredentials
+    default:
+        return nil, fmt.Errorf("failed to log in: status %d, body: %s", resp.StatusCode(), resp.String())
+    }
+
+    var tokenResp TokenResponse
+    if err := json.Unmarshal(resp.Body(), &tokenResp); err != nil {
+        return nil, fmt.Errorf("failed to parse token response: %w", err)
+    }
+    return &tokenResp, nil
+}
+
 func (k *KeycloakService) GetUserInfo(ctx context.Context, token string) (*KeycloakUser, error) {
     if token == "" {
         return nil, fmt.Errorf("token is required")
     }
 
-    userInfoURL := fmt.Sprintf("%s/realms/%s/protocol/openid_connect/userinfo", k.baseURL, k.realm)
+    userInfoURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/userinfo", k.baseURL, k.realm)
     
     resp, err := k.client.R().
         SetContext(ctx).
@@ -138,12 +190,21 @@ func (k *KeycloakService) GetUserInfo(ctx context.Context, token string) (*Keycl
         return nil, fmt.Errorf("failed to get user info: status %d, body: %s", resp.StatusCode(), resp.String())
     }
 
-    var userInfo KeycloakUser
-    if err := json.Unmarshal(resp.Body(), &userInfo); err != nil {
+    // The userinfo endpoint returns OIDC claims, not the admin API representation
+    var claims userInfoClaims
+    if err := json.Unmarshal(resp.Body(), &claims); err != nil {
         return nil, fmt.Errorf("failed to parse user info: %w", err)
     }
 
-    return &userInfo, nil
+    return &KeycloakUser{
+        ID:        claims.Subject,
+        Username:  claims.PreferredUsername,
+        Email:     claims.Email,
+        EmailVerified: claims.EmailVerified,
+        FirstName: claims.GivenName,
+        LastName:  claims.FamilyName,
+        Enabled:   true,
+    }, nil
 }
 
 func (k *KeycloakService) CreateUser(ctx context.Context, user *KeycloakUser) (string, error) {
@@ -268,6 +329,8 @@ func (k *KeycloakService) UpdateUser(ctx context.Context, userID string, user *K
         return nil
     case http.StatusNotFound:
         return fmt.Errorf("user not found")
+    case http.StatusConflict:
+        return fmt.Errorf("user with username or email already exists")
     case http.StatusBadRequest:
         return fmt.Errorf("invalid user data: %s", resp.String())
     case http.StatusUnauthorized:
@@ -310,12 +373,44 @@ func (k *KeycloakService) DeleteUser(ctx context.Context, userID string) error {
     }
 }
 
+// Ping checks that Keycloak is reachable by fetching the realm's OpenID configuration
+func (k *KeycloakService) Ping(ctx context.Context) error {
+    configURL := fmt.Sprintf("%s/realms/%s/.well-known/openid-configuration", k.baseURL, k.realm)
+
+    resp, err := k.client.R().
+        SetContext(ctx).
+        Get(configURL)
+    if err != nil {
+        return fmt.Errorf("failed to reach Keycloak: %w", err)
+    }
+    if resp.StatusCode() != http.StatusOK {
+        return fmt.Errorf("keycloak returned status %d", resp.StatusCode())
+    }
+    return nil
+}
+
+// TokenIntrospection is what Keycloak reports about an access token
+type TokenIntrospection struct {
+    Active     bool
+    RealmRoles []string
+}
+
+// ValidateToken reports whether Keycloak considers the token active
 func (k *KeycloakService) ValidateToken(ctx context.Context, token string) (bool, error) {
+    introspection, err := k.IntrospectToken(ctx, token)
+    if err != nil {
+        return false, err
+    }
+    return introspection.Active, nil
+}
+
+// IntrospectToken asks Keycloak whether the token is active and which realm roles it carries
+func (k *KeycloakService) IntrospectToken(ctx context.Context, token string) (*TokenIntrospection, error) {
     if token == "" {
-        return false, fmt.Errorf("token is required")
+        return nil, fmt.Errorf("token is required")
     }
 
-    introspectURL := fmt.Sprintf("%s/realms/%s/protocol/openid_connect/token/introspect", k.baseURL, k.realm)
+    introspectURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token/introspect", k.baseURL, k.realm)
     
     resp, err := k.client.R().
         SetContext(ctx).
@@ -328,22 +423,29 @@ func (k *KeycloakService) ValidateToken(ctx context.Context, token string) (bool
         Post(introspectURL)
 
     if err != nil {
-        return false, fmt.Errorf("failed to validate token: %w", err)
+        return nil, fmt.Errorf("failed to validate token: %w", err)
     }
 
     if resp.StatusCode() != http.StatusOK {
-        return false, fmt.Errorf("failed to validate token: status %d", resp.StatusCode())
+        return nil, fmt.Errorf("failed to validate token: status %d", resp.StatusCode())
     }
 
-    var introspectionResp map[string]interface{}
+    var introspectionResp struct {
+        Active      *bool `json:"active"`
+        RealmAccess struct {
+            Roles []string `json:"roles"`
+        } `json:"realm_access"`
+    }
     if err := json.Unmarshal(resp.Body(), &introspectionResp); err != nil {
-        return false, fmt.Errorf("failed to parse introspection response: %w", err)
+        return nil, fmt.Errorf("failed to parse introspection response: %w", err)
     }
 
-    active, ok := introspectionResp["active"].(bool)
-    if !ok {
-        return false, fmt.Errorf("invalid introspection response format")
+    if introspectionResp.Active == nil {
+        return nil, fmt.Errorf("invalid introspection response format")
     }
 
-    return active, nil
+    return &TokenIntrospection{
+        Active:     *introspectionResp.Active,
+        RealmRoles: introspectionResp.RealmAccess.Roles,
+    }, nil
 }
\ No newline at end of file
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"text/template"
+)
+
+// NotificationTemplate identifies the email template used for a notification
+type NotificationTemplate string
+
+const (
+	TemplateProjectInvitation NotificationTemplate = "project_invitation"
+	TemplateCollaboratorAdded NotificationTemplate = "collaborator_added"
+	TemplateOwnershipTransfer NotificationTemplate = "ownership_transfer"
+)
+
+// Notification is a message to deliver to a single recipient
+type Notification struct {
+	To       string
+	Template NotificationTemplate
+	Data     map[string]string
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+// NoopNotifier discards every notification, used when email is disabled
+type NoopNotifier struct{}
+
+// Notify implements Notifier
+func (NoopNotifier) Notify(notification Notification) error {
+	return nil
+}
+
+// emailTemplate holds the subject and body of a notification email
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// emailTemplates maps every notification template to its email content
+var emailTemplates = map[NotificationTemplate]emailTemplate{
+	TemplateProjectInvitation: newEmailTemplate(
+		"You've been invited to {{.ProjectName}}",
+		"Hi {{.RecipientName}},\n\nYou have been invited to join the project \"{{.ProjectName}}\" as {{.Role}}.\nOpen CollabHub Music to accept the invitation.\n",
+	),
+	TemplateCollaboratorAdded: newEmailTemplate(
+		"You've been added to {{.ProjectName}}",
+		"Hi {{.RecipientName}},\n\nYou have been added to the project \"{{.ProjectName}}\" as {{.Role}}.\n",
+	),
+	TemplateOwnershipTransfer: newEmailTemplate(
+		"You are now the owner of {{.ProjectName}}",
+		"Hi {{.RecipientName}},\n\nOwnership of the project \"{{.ProjectName}}\" has been transferred to you.\n",
+	),
+}
+
+func newEmailTemplate(subject, body string) emailTemplate {
+	return emailTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+// SMTPNotifier sends notifications as plain-text emails over SMTP
+type SMTPNotifier struct {
+	host        string
+	port        int
+	username    string
+	password    string
+	fromName    string
+	fromAddress string
+}
+
+// NewSMTPNotifier creates a new SMTP notifier
+func NewSMTPNotifier(host string, port int, username, password, fromName, fromAddress string) *SMTPNotifier {
+	return &SMTPNotifier{
+		host:        host,
+		port:        port,
+		username:    username,
+		password:    password,
+		fromName:    fromName,
+		fromAddress: fromAddress,
+	}
+}
+
+// headerLineBreaks turns line breaks into spaces, so that values written into headers
+// can't start a new header
+var headerLineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// Notify renders the notification template and sends it to the recipient. The subject is
+// rendered from user-provided data such as project names, so it is kept to one line and
+// encoded as an RFC 2047 word when it isn't plain ASCII.
+func (n *SMTPNotifier) Notify(notification Notification) error {
+	if notification.To == "" {
+		return fmt.Errorf("notification recipient is required")
+	}
+	to, err := mail.ParseAddress(notification.To)
+	if err != nil {
+		return fmt.Errorf("invalid notification recipient: %w", err)
+	}
+
+	tmpl, ok := emailTemplates[notification.Template]
+	if !ok {
+		return fmt.Errorf("unknown notification template: %s", notification.Template)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, notification.Data); err != nil {
+		return fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := tmpl.body.Execute(&body, notification.Data); err != nil {
+		return fmt.Errorf("failed to render body: %w", err)
+	}
+
+	from := mail.Address{Name: n.fromName, Address: n.fromAddress}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", headerLineBreaks.Replace(subject.String())))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", n.host, n.port)
+	if err := smtp.SendMail(addr, auth, n.fromAddress, []string{to.Address}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
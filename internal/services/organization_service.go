@@ -1,68 +1,231 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"collabhub-music-backend/internal/models"
 	"collabhub-music-backend/internal/repository"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var validate = validator.New()
+
 // OrganizationService provides organization-related business logic
-type OrganizationServiceInterface struct {
-	orgRepo  repository.OrganizationRepositoryInterface
-	userRepo repository.UserRepositoryInterface
+type OrganizationService struct {
+	orgRepo     repository.OrganizationRepositoryInterface
+	userRepo    repository.UserRepositoryInterface
+	projectRepo repository.ProjectRepositoryInterface
+	policy      *PolicyService
 }
 
 // NewOrganizationService creates a new instance of OrganizationService
-func NewOrganizationService(orgRepo repository.OrganizationRepositoryInterface, userRepo repository.UserRepositoryInterface) *OrganizationServiceInterface {
-	return &OrganizationServiceInterface{
-		orgRepo:  orgRepo,
-		userRepo: userRepo,
+func NewOrganizationService(orgRepo repository.OrganizationRepositoryInterface, userRepo repository.UserRepositoryInterface, projectRepo repository.ProjectRepositoryInterface) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:     orgRepo,
+		userRepo:    userRepo,
+		projectRepo: projectRepo,
+		policy:      NewPolicyService(projectRepo, orgRepo),
 	}
 }
 
 // CreateOrganization creates a new organization
-func (s *OrganizationServiceInterface) CreateOrganization(org *models.Organization) error {
+func (s *OrganizationService) CreateOrganization(org *models.Organization) error {
+	website, err := normalizeWebsite(org.Website)
+	if err != nil {
+		return err
+	}
+	org.Website = website
+
 	return s.orgRepo.Create(org)
 }
 
 // GetOrganizationByID retrieves an organization by ID
-func (s *OrganizationServiceInterface) GetOrganizationByID(id uuid.UUID) (*models.Organization, error) {
+func (s *OrganizationService) GetOrganizationByID(id uuid.UUID) (*models.Organization, error) {
 	return s.orgRepo.GetByID(id)
 }
 
 // UpdateOrganization updates an organization
-func (s *OrganizationServiceInterface) UpdateOrganization(org *models.Organization) error {
+func (s *OrganizationService) UpdateOrganization(org *models.Organization) error {
+	website, err := normalizeWebsite(org.Website)
+	if err != nil {
+		return err
+	}
+	org.Website = website
+
 	return s.orgRepo.Update(org)
 }
 
 // DeleteOrganization deletes an organization
-func (s *OrganizationServiceInterface) DeleteOrganization(id uuid.UUID) error {
+func (s *OrganizationService) DeleteOrganization(id uuid.UUID) error {
 	return s.orgRepo.Delete(id)
 }
 
 // GetOrganizationsByUserID gets organizations for a user
-func (s *OrganizationServiceInterface) GetOrganizationsByUserID(userID uuid.UUID) ([]*models.Organization, error) {
+func (s *OrganizationService) GetOrganizationsByUserID(userID uuid.UUID) ([]*models.Organization, error) {
 	return s.orgRepo.GetByUserID(userID)
 }
 
 // AddMember adds a member to an organization
-func (s *OrganizationServiceInterface) AddMember(member *models.OrganizationMember) error {
+func (s *OrganizationService) AddMember(member *models.OrganizationMember) error {
 	return s.orgRepo.AddMember(member)
 }
 
+// AddUserToOrganization adds an existing user to an organization as a member. Users who
+// are already members are ErrConflict.
+func (s *OrganizationService) AddUserToOrganization(organizationID, userID uuid.UUID) error {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	member, err := s.policy.findMember(organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if member != nil {
+		return fmt.Errorf("%w: user is already a member", ErrConflict)
+	}
+
+	joinedAt := time.Now()
+	return s.orgRepo.AddMember(&models.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Role:           models.OrganizationRoleMember,
+		InvitedAt:      joinedAt,
+		JoinedAt:       &joinedAt,
+	})
+}
+
 // RemoveMember removes a member from an organization
-func (s *OrganizationServiceInterface) RemoveMember(organizationID, userID uuid.UUID) error {
+func (s *OrganizationService) RemoveMember(organizationID, userID uuid.UUID) error {
 	return s.orgRepo.RemoveMember(organizationID, userID)
 }
 
 // GetMembers gets all members of an organization
-func (s *OrganizationServiceInterface) GetMembers(organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+func (s *OrganizationService) GetMembers(organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
 	return s.orgRepo.GetMembers(organizationID)
 }
 
-// ListOrganizations lists all organizations (placeholder)
-func (s *OrganizationServiceInterface) ListOrganizations() ([]*models.Organization, error) {
-	return nil, fmt.Errorf("not implemented")
+// GetOrganizationMembers returns a page of an organization's members along with the total count.
+// Only members of the organization may list them.
+func (s *OrganizationService) GetOrganizationMembers(userID, organizationID uuid.UUID, limit, offset int) ([]*models.OrganizationMember, int64, error) {
+	org, err := s.getOrganization(organizationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.policy.CanAccessOrg(userID, org); err != nil {
+		return nil, 0, err
+	}
+
+	return s.orgRepo.GetMembersPage(organizationID, limit, offset)
+}
+
+// GetOrganizationProjects returns a page of an organization's projects along with the total count.
+// Only members of the organization may list them.
+func (s *OrganizationService) GetOrganizationProjects(userID, organizationID uuid.UUID, limit, offset int) ([]*models.Project, int64, error) {
+	org, err := s.getOrganization(organizationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.policy.CanAccessOrg(userID, org); err != nil {
+		return nil, 0, err
+	}
+
+	return s.projectRepo.GetByOrganizationID(organizationID, limit, offset)
+}
+
+// ListPublicOrganizations returns a page of the public organizations whose name or slug
+// contains the query, by name. An empty query lists them all.
+func (s *OrganizationService) ListPublicOrganizations(query string, limit, offset int) (*models.PublicOrganizationPage, error) {
+	organizations, total, err := s.orgRepo.SearchPublic(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PublicOrganizationPage{
+		Organizations: organizations,
+		Total:         total,
+		Limit:         limit,
+		Offset:        offset,
+	}, nil
+}
+
+// GetVisibleOrganization returns an organization the user can see. Public organizations
+// are visible to everyone, private ones only to their members; others get ErrNotFound.
+func (s *OrganizationService) GetVisibleOrganization(userID, organizationID uuid.UUID) (*models.Organization, error) {
+	org, err := s.getOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanViewOrg(userID, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// GetOrganizationByName returns the organization with exactly this name if the user can
+// see it; private organizations of others are ErrNotFound
+func (s *OrganizationService) GetOrganizationByName(userID uuid.UUID, name string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanViewOrg(userID, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// getOrganization loads an organization, translating a missing record into ErrNotFound
+func (s *OrganizationService) getOrganization(organizationID uuid.UUID) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(organizationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return org, err
+}
+
+// isPublicOrganization reports whether non-members can see the organization
+func isPublicOrganization(org *models.Organization) bool {
+	return org.Visibility != models.OrganizationVisibilityPrivate
+}
+
+// normalizeWebsite validates an organization website and returns it trimmed, with a lowercase
+// scheme and host. Only absolute http and https URLs are accepted; an empty value is allowed.
+func normalizeWebsite(raw string) (string, error) {
+	website := strings.TrimSpace(raw)
+	if website == "" {
+		return "", nil
+	}
+
+	invalid := &FieldError{Field: "website", Message: "must be a valid http or https URL"}
+	if err := validate.Var(website, "url"); err != nil {
+		return "", invalid
+	}
+
+	parsed, err := url.Parse(website)
+	if err != nil || parsed.Host == "" {
+		return "", invalid
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", invalid
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+
+	return parsed.String(), nil
 }
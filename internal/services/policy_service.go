@@ -0,0 +1,228 @@
+package services
+
+import (
+	"collabhub-music-backend/internal/models"
+	"collabhub-music-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// PolicyService decides what a user may do with projects and organizations. Each check
+// returns nil when the action is allowed, ErrForbidden when the user can see the resource
+// but may not act on it, and ErrNotFound when the resource should stay hidden from them.
+type PolicyService struct {
+	projectRepo repository.ProjectRepositoryInterface
+	orgRepo     repository.OrganizationRepositoryInterface
+}
+
+// NewPolicyService creates a new instance of PolicyService
+func NewPolicyService(projectRepo repository.ProjectRepositoryInterface, orgRepo repository.OrganizationRepositoryInterface) *PolicyService {
+	return &PolicyService{
+		projectRepo: projectRepo,
+		orgRepo:     orgRepo,
+	}
+}
+
+// CanAccessProject allows the owner, the creator and the collaborators of a project
+func (p *PolicyService) CanAccessProject(userID uuid.UUID, project *models.Project) error {
+	isMember, err := p.IsProjectMember(userID, project)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return denyAccess(project.IsPublic)
+	}
+	return nil
+}
+
+// CanEditProject allows the owner and admins of a project to change it and its collaborators
+func (p *PolicyService) CanEditProject(userID uuid.UUID, project *models.Project) error {
+	if err := p.CanAccessProject(userID, project); err != nil {
+		return err
+	}
+	if project.OwnerID == userID {
+		return nil
+	}
+
+	collaborator, err := p.findCollaborator(project.ID, userID)
+	if err != nil {
+		return err
+	}
+	if collaborator == nil || collaborator.Role != models.ProjectRoleAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanEditFiles allows the members of a project other than viewers to change its files
+func (p *PolicyService) CanEditFiles(userID uuid.UUID, project *models.Project) error {
+	if err := p.CanAccessProject(userID, project); err != nil {
+		return err
+	}
+	if project.OwnerID == userID || project.CreatedBy == userID {
+		return nil
+	}
+
+	collaborator, err := p.findCollaborator(project.ID, userID)
+	if err != nil {
+		return err
+	}
+	if collaborator == nil || collaborator.Role == models.ProjectRoleViewer {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanTransferProject allows only the owner to hand a project over to someone else
+func (p *PolicyService) CanTransferProject(userID uuid.UUID, project *models.Project) error {
+	return p.requireOwner(userID, project)
+}
+
+// CanDeleteProject allows only the owner to delete a project
+func (p *PolicyService) CanDeleteProject(userID uuid.UUID, project *models.Project) error {
+	return p.requireOwner(userID, project)
+}
+
+// requireOwner allows the owner of a project and denies everyone else
+func (p *PolicyService) requireOwner(userID uuid.UUID, project *models.Project) error {
+	if project.OwnerID == userID {
+		return nil
+	}
+	isMember, err := p.IsProjectMember(userID, project)
+	if err != nil {
+		return err
+	}
+	return denyAccess(project.IsPublic || isMember)
+}
+
+// IsProjectMember reports whether the user owns, created or collaborates on the project.
+// Invited users only become members once they accept.
+func (p *PolicyService) IsProjectMember(userID uuid.UUID, project *models.Project) (bool, error) {
+	if project.OwnerID == userID || project.CreatedBy == userID {
+		return true, nil
+	}
+
+	collaborator, err := p.findCollaborator(project.ID, userID)
+	if err != nil {
+		return false, err
+	}
+	return collaborator != nil, nil
+}
+
+// findCollaborator returns the user's collaborator row on the project, or nil if there is
+// none. Pending invitations, which have no JoinedAt yet, grant nothing and are left out.
+func (p *PolicyService) findCollaborator(projectID, userID uuid.UUID) (*models.ProjectCollaborator, error) {
+	collaborator, err := findCollaboratorRow(p.projectRepo, projectID, userID)
+	if err != nil || collaborator == nil || collaborator.JoinedAt == nil {
+		return nil, err
+	}
+	return collaborator, nil
+}
+
+// findCollaboratorRow returns the user's collaborator row on the project, whether they joined
+// or are only invited, or nil if there is none
+func findCollaboratorRow(projectRepo repository.ProjectRepositoryInterface, projectID, userID uuid.UUID) (*models.ProjectCollaborator, error) {
+	collaborators, err := projectRepo.GetCollaborators(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, collaborator := range collaborators {
+		if collaborator.UserID == userID {
+			return collaborator, nil
+		}
+	}
+	return nil, nil
+}
+
+// CanViewOrg allows everyone to see public organizations and only members to see private ones
+func (p *PolicyService) CanViewOrg(userID uuid.UUID, org *models.Organization) error {
+	if isPublicOrganization(org) {
+		return nil
+	}
+	member, err := p.findMember(org.ID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CanAccessOrg allows only members to look inside an organization, e.g. at its members or projects
+func (p *PolicyService) CanAccessOrg(userID uuid.UUID, org *models.Organization) error {
+	member, err := p.findMember(org.ID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return denyAccess(isPublicOrganization(org))
+	}
+	return nil
+}
+
+// CanEditOrg allows only the creator of an organization to change its details
+func (p *PolicyService) CanEditOrg(userID uuid.UUID, org *models.Organization) error {
+	return p.requireOrgCreator(userID, org)
+}
+
+// CanDeleteOrg allows only the creator of an organization to delete it
+func (p *PolicyService) CanDeleteOrg(userID uuid.UUID, org *models.Organization) error {
+	return p.requireOrgCreator(userID, org)
+}
+
+// CanManageMembers allows the creator and the owners and admins of an organization to add
+// and remove its members
+func (p *PolicyService) CanManageMembers(userID uuid.UUID, org *models.Organization) error {
+	return p.requireOrgManager(userID, org)
+}
+
+// CanCreateOrgProject allows the creator and the owners and admins of an organization to
+// create projects in it
+func (p *PolicyService) CanCreateOrgProject(userID uuid.UUID, org *models.Organization) error {
+	return p.requireOrgManager(userID, org)
+}
+
+// requireOrgManager allows the creator and the owners and admins of an organization; others
+// who can see it are forbidden
+func (p *PolicyService) requireOrgManager(userID uuid.UUID, org *models.Organization) error {
+	if err := p.CanViewOrg(userID, org); err != nil {
+		return err
+	}
+	if org.CreatedBy == userID {
+		return nil
+	}
+
+	member, err := p.findMember(org.ID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || (member.Role != models.OrganizationRoleOwner && member.Role != models.OrganizationRoleAdmin) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// requireOrgCreator allows the creator of an organization; others who can see it are forbidden
+func (p *PolicyService) requireOrgCreator(userID uuid.UUID, org *models.Organization) error {
+	if err := p.CanViewOrg(userID, org); err != nil {
+		return err
+	}
+	if org.CreatedBy != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// findMember returns the user's membership of the organization, or nil if they aren't a member
+func (p *PolicyService) findMember(organizationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	members, err := p.orgRepo.GetMembers(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		if member.UserID == userID {
+			return member, nil
+		}
+	}
+	return nil, nil
+}
@@ -1,61 +1,792 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"collabhub-music-backend/internal/models"
 	"collabhub-music-backend/internal/repository"
+	"collabhub-music-backend/pkg/logger"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // ProjectService provides project-related business logic
-type ProjectServiceInterface struct {
+type ProjectService struct {
 	projectRepo repository.ProjectRepositoryInterface
+	orgRepo     repository.OrganizationRepositoryInterface
 	userRepo    repository.UserRepositoryInterface
+	branchRepo  repository.BranchRepositoryInterface
+	fileRepo    repository.FileRepositoryInterface
+	notifier    Notifier
+	policy      *PolicyService
+
+	// defaultRole is given to collaborators added or invited without a role
+	defaultRole string
+
+	// summaries caches each user's dashboard summary for summaryTTL
+	summaryMu sync.Mutex
+	summaries map[uuid.UUID]cachedSummary
 }
 
-// NewProjectService creates a new instance of ProjectService
-func NewProjectService(projectRepo repository.ProjectRepositoryInterface, userRepo repository.UserRepositoryInterface) *ProjectServiceInterface {
-	return &ProjectServiceInterface{
+// cachedSummary is a user's dashboard summary and when it has to be counted again
+type cachedSummary struct {
+	summary *models.UserSummary
+	expires time.Time
+}
+
+// summaryTTL is how long a user's dashboard summary is reused before it is counted again;
+// recentActivityWindow is how far back it counts activity
+const (
+	summaryTTL           = 30 * time.Second
+	recentActivityWindow = 7 * 24 * time.Hour
+)
+
+// NewProjectService creates a new instance of ProjectService.
+// A nil notifier disables email notifications.
+func NewProjectService(projectRepo repository.ProjectRepositoryInterface, orgRepo repository.OrganizationRepositoryInterface, userRepo repository.UserRepositoryInterface, branchRepo repository.BranchRepositoryInterface, fileRepo repository.FileRepositoryInterface, notifier Notifier) *ProjectService {
+	if notifier == nil {
+		notifier = NoopNotifier{}
+	}
+	return &ProjectService{
 		projectRepo: projectRepo,
+		orgRepo:     orgRepo,
 		userRepo:    userRepo,
+		branchRepo:  branchRepo,
+		fileRepo:    fileRepo,
+		notifier:    notifier,
+		policy:      NewPolicyService(projectRepo, orgRepo),
+		defaultRole: models.ProjectRoleViewer,
+		summaries:   make(map[uuid.UUID]cachedSummary),
+	}
+}
+
+// SetDefaultCollaboratorRole changes the role given to collaborators added or invited
+// without one. It must be a role that can be granted: admin, collaborator or viewer.
+func (s *ProjectService) SetDefaultCollaboratorRole(role string) error {
+	if !grantableRoles[role] {
+		return fmt.Errorf("default collaborator role %q must be one of admin, collaborator, viewer", role)
 	}
+	s.defaultRole = role
+	return nil
 }
 
-// CreateProject creates a new project
-func (s *ProjectServiceInterface) CreateProject(project *models.Project) error {
+// CreateProject creates a new project. A project created in an organization must name one
+// its creator owns or administers; one they can't see is reported as not found.
+func (s *ProjectService) CreateProject(project *models.Project) error {
+	if project.OrganizationID != nil {
+		org, err := s.orgRepo.GetByID(*project.OrganizationID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.policy.CanCreateOrgProject(project.CreatedBy, org); err != nil {
+			return err
+		}
+	}
 	return s.projectRepo.Create(project)
 }
 
 // GetProjectByID retrieves a project by ID
-func (s *ProjectServiceInterface) GetProjectByID(id uuid.UUID) (*models.Project, error) {
+func (s *ProjectService) GetProjectByID(id uuid.UUID) (*models.Project, error) {
 	return s.projectRepo.GetByID(id)
 }
 
+// GetProject returns a project the user is a member of
+func (s *ProjectService) GetProject(userID, projectID uuid.UUID) (*models.Project, error) {
+	return s.getMemberProject(userID, projectID)
+}
+
 // GetProjectsByUserID retrieves projects by user ID
-func (s *ProjectServiceInterface) GetProjectsByUserID(userID uuid.UUID) ([]*models.Project, error) {
+func (s *ProjectService) GetProjectsByUserID(userID uuid.UUID) ([]*models.Project, error) {
 	return s.projectRepo.GetByUserID(userID)
 }
 
-// UpdateProject updates a project
-func (s *ProjectServiceInterface) UpdateProject(project *models.Project) error {
-	return s.projectRepo.Update(project)
+// projectRoles are the roles a user's project list can be filtered by
+var projectRoles = map[string]bool{
+	models.ProjectRoleOwner:        true,
+	models.ProjectRoleAdmin:        true,
+	models.ProjectRoleCollaborator: true,
+	models.ProjectRoleViewer:       true,
+}
+
+// GetUserProjects returns a page of the projects a user is a member of with the user's role
+// in each, most recently active first. An empty role lists projects of every role.
+func (s *ProjectService) GetUserProjects(userID uuid.UUID, role string, limit, offset int) (*models.UserProjectPage, error) {
+	if role != "" && !projectRoles[role] {
+		return nil, &FieldError{Field: "role", Message: "must be one of owner, admin, collaborator, viewer"}
+	}
+
+	projects, total, err := s.projectRepo.GetUserProjects(userID, role, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserProjectPage{
+		Projects: projects,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+// ListPublicProjects returns a page of the public projects for discovery, in the given
+// order: recent, the default, or popular
+func (s *ProjectService) ListPublicProjects(sort string, limit, offset int) (*models.PublicProjectPage, error) {
+	if sort == "" {
+		sort = models.PublicProjectSortRecent
+	}
+	if sort != models.PublicProjectSortRecent && sort != models.PublicProjectSortPopular {
+		return nil, &FieldError{Field: "sort", Message: "must be one of recent, popular"}
+	}
+
+	projects, total, err := s.projectRepo.GetPublic(sort, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PublicProjectPage{
+		Projects: projects,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+// FavoriteProject adds a project the user can access to their favorites
+func (s *ProjectService) FavoriteProject(userID, projectID uuid.UUID) error {
+	if _, err := s.getMemberProject(userID, projectID); err != nil {
+		return err
+	}
+	return s.projectRepo.AddFavorite(userID, projectID)
+}
+
+// UnfavoriteProject removes a project from the user's favorites. It succeeds for
+// projects that aren't favorites, or that the user can no longer access.
+func (s *ProjectService) UnfavoriteProject(userID, projectID uuid.UUID) error {
+	return s.projectRepo.RemoveFavorite(userID, projectID)
+}
+
+// ListFavoriteProjects lists the user's favorite projects, most recently favorited first.
+// Projects the user can no longer access are left out.
+func (s *ProjectService) ListFavoriteProjects(userID uuid.UUID) ([]*models.FavoriteProject, error) {
+	favorites, err := s.projectRepo.GetFavorites(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]*models.FavoriteProject, 0, len(favorites))
+	for _, favorite := range favorites {
+		isMember, err := s.policy.IsProjectMember(userID, &favorite.Project)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			continue
+		}
+		projects = append(projects, &models.FavoriteProject{
+			Project:     favorite.Project,
+			FavoritedAt: favorite.CreatedAt,
+		})
+	}
+	return projects, nil
+}
+
+// GetUserSummary counts the user's projects, organizations, the storage of the projects
+// they own and the activity in their projects over the last week. A summary is reused for
+// summaryTTL, so counts may lag behind by that long.
+func (s *ProjectService) GetUserSummary(userID uuid.UUID) (*models.UserSummary, error) {
+	now := time.Now()
+	s.summaryMu.Lock()
+	cached, ok := s.summaries[userID]
+	s.summaryMu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.summary, nil
+	}
+
+	summary, err := s.projectRepo.GetUserSummary(userID, now.Add(-recentActivityWindow))
+	if err != nil {
+		return nil, err
+	}
+	summary.OrganizationCount, err = s.orgRepo.CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.summaryMu.Lock()
+	defer s.summaryMu.Unlock()
+	for id, other := range s.summaries {
+		if !now.Before(other.expires) {
+			delete(s.summaries, id)
+		}
+	}
+	s.summaries[userID] = cachedSummary{summary: summary, expires: now.Add(summaryTTL)}
+	return summary, nil
+}
+
+// UpdateProject changes the name, description or visibility of a project the user owns or
+// administers and returns the updated project
+func (s *ProjectService) UpdateProject(userID, projectID uuid.UUID, update *models.UpdateProjectRequest) (*models.Project, error) {
+	project, err := s.getManageableProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		project.Name = *update.Name
+	}
+	if update.Description != nil {
+		project.Description = *update.Description
+	}
+	if update.IsPublic != nil {
+		project.IsPublic = *update.IsPublic
+	}
+
+	if err := s.projectRepo.Update(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// DeleteProject soft-deletes a project; only its owner may delete it
+func (s *ProjectService) DeleteProject(userID, projectID uuid.UUID) error {
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.CanDeleteProject(userID, project); err != nil {
+		return err
+	}
+	return s.projectRepo.Delete(projectID)
+}
+
+// BatchDeleteProjects soft-deletes the projects among ids that the user owns, all in one
+// transaction, and reports for each id whether it was deleted, forbidden or not found.
+// Projects the user can't see are reported as not found and repeated ids once.
+func (s *ProjectService) BatchDeleteProjects(userID uuid.UUID, ids []uuid.UUID) ([]models.BatchDeleteResult, error) {
+	results := make([]models.BatchDeleteResult, 0, len(ids))
+	var owned []uuid.UUID
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := models.BatchDeleteResult{ProjectID: id, Status: models.BatchDeleteDeleted}
+		project, err := s.getProject(id)
+		if err == nil {
+			err = s.policy.CanDeleteProject(userID, project)
+		}
+		switch {
+		case err == nil:
+			owned = append(owned, id)
+		case errors.Is(err, ErrNotFound):
+			result.Status = models.BatchDeleteNotFound
+		case errors.Is(err, ErrForbidden):
+			result.Status = models.BatchDeleteForbidden
+		default:
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := s.projectRepo.DeleteBatch(owned); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// grantableRoles are the roles a collaborator can be given; ownership is only
+// ever changed through TransferOwnership
+var grantableRoles = map[string]bool{
+	models.ProjectRoleAdmin:        true,
+	models.ProjectRoleCollaborator: true,
+	models.ProjectRoleViewer:       true,
 }
 
-// DeleteProject deletes a project
-func (s *ProjectServiceInterface) DeleteProject(id uuid.UUID) error {
-	return s.projectRepo.Delete(id)
+// AddCollaborator adds a user to a project with the given role, or the default role when it
+// is empty. The role is validated here so every entry path is covered: owner and unknown
+// roles are rejected. Only the owner and admins of the project may add collaborators. Users
+// added directly join immediately.
+func (s *ProjectService) AddCollaborator(userID, projectID, collaboratorID uuid.UUID, role string) error {
+	now := time.Now()
+	collaborator := &models.ProjectCollaborator{
+		ProjectID: projectID,
+		UserID:    collaboratorID,
+		Role:      role,
+		InvitedAt: now,
+		JoinedAt:  &now,
+	}
+	if err := s.createCollaborator(userID, collaborator); err != nil {
+		return err
+	}
+
+	s.notifyUser(projectID, collaboratorID, TemplateCollaboratorAdded, collaborator.Role)
+	return nil
 }
 
-// AddCollaborator adds a collaborator to a project
-func (s *ProjectServiceInterface) AddCollaborator(collaborator *models.ProjectCollaborator) error {
+// InviteCollaborator invites a user to a project with the given role. The invitation is a
+// collaborator row without JoinedAt until the user accepts it. The same role and permission
+// rules as AddCollaborator apply.
+func (s *ProjectService) InviteCollaborator(userID, projectID, inviteeID uuid.UUID, role string) error {
+	collaborator := &models.ProjectCollaborator{
+		ProjectID: projectID,
+		UserID:    inviteeID,
+		Role:      role,
+		InvitedAt: time.Now(),
+	}
+	if err := s.createCollaborator(userID, collaborator); err != nil {
+		return err
+	}
+
+	s.notifyUser(projectID, inviteeID, TemplateProjectInvitation, collaborator.Role)
+	return nil
+}
+
+// AcceptInvitation records that the user joined a project they were invited to.
+// Accepting an invitation that was already accepted leaves JoinedAt unchanged.
+func (s *ProjectService) AcceptInvitation(userID, projectID uuid.UUID) (*models.ProjectCollaborator, error) {
+	if _, err := s.getProject(projectID); err != nil {
+		return nil, err
+	}
+
+	collaborator, err := s.findCollaborator(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if collaborator == nil {
+		return nil, ErrNotFound
+	}
+	if collaborator.JoinedAt != nil {
+		return collaborator, nil
+	}
+
+	now := time.Now()
+	if err := s.projectRepo.MarkCollaboratorJoined(collaborator.ID, now); err != nil {
+		return nil, err
+	}
+	collaborator.JoinedAt = &now
+	return collaborator, nil
+}
+
+// createCollaborator fills in the default role, validates the role and the caller's
+// permissions, then stores the collaborator row
+func (s *ProjectService) createCollaborator(userID uuid.UUID, collaborator *models.ProjectCollaborator) error {
+	if collaborator.Role == "" {
+		collaborator.Role = s.defaultRole
+	}
+	if collaborator.Role == models.ProjectRoleOwner {
+		return &FieldError{Field: "role", Message: "owner can't be granted, transfer ownership instead"}
+	}
+	if !grantableRoles[collaborator.Role] {
+		return &FieldError{Field: "role", Message: "must be one of admin, collaborator, viewer"}
+	}
+
+	if _, err := s.getManageableProject(userID, collaborator.ProjectID); err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.GetByID(collaborator.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	existing, err := s.findCollaborator(collaborator.ProjectID, collaborator.UserID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return &FieldError{Field: "user_id", Message: "is already a collaborator or invited"}
+	}
+
 	return s.projectRepo.AddCollaborator(collaborator)
 }
 
-// RemoveCollaborator removes a collaborator from a project
-func (s *ProjectServiceInterface) RemoveCollaborator(projectID, userID uuid.UUID) error {
-	return s.projectRepo.RemoveCollaborator(projectID, userID)
+// UpdateProjectSettings validates and stores a project's settings, leaving the rest of the
+// project untouched. Only the owner and admins of the project may change them.
+func (s *ProjectService) UpdateProjectSettings(userID, projectID uuid.UUID, settings models.ProjectSettings) (*models.Project, error) {
+	if err := validateProjectSettings(settings); err != nil {
+		return nil, err
+	}
+
+	project, err := s.getManageableProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.projectRepo.UpdateSettings(projectID, settings); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	project.Settings = settings
+	return project, nil
+}
+
+// getManageableProject loads a project the user owns or administers
+func (s *ProjectService) getManageableProject(userID, projectID uuid.UUID) (*models.Project, error) {
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanEditProject(userID, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// findCollaborator returns the user's collaborator row on the project, including a pending
+// invitation, or nil if there is none
+func (s *ProjectService) findCollaborator(projectID, userID uuid.UUID) (*models.ProjectCollaborator, error) {
+	return findCollaboratorRow(s.projectRepo, projectID, userID)
+}
+
+// TransferOwnership makes another user the owner of a project; only the current owner can transfer it
+func (s *ProjectService) TransferOwnership(userID, projectID, newOwnerID uuid.UUID) error {
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.CanTransferProject(userID, project); err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.GetByID(newOwnerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	project.OwnerID = newOwnerID
+	if err := s.projectRepo.Update(project); err != nil {
+		return err
+	}
+
+	s.notifyUser(projectID, newOwnerID, TemplateOwnershipTransfer, "owner")
+	return nil
+}
+
+// RemoveCollaborator removes a collaborator from a project the user owns or administers
+func (s *ProjectService) RemoveCollaborator(userID, projectID, collaboratorID uuid.UUID) error {
+	if _, err := s.getManageableProject(userID, projectID); err != nil {
+		return err
+	}
+	return s.projectRepo.RemoveCollaborator(projectID, collaboratorID)
+}
+
+// ListCollaborators returns the collaborators of a project the user is a member of,
+// including pending invitations, which have no JoinedAt yet
+func (s *ProjectService) ListCollaborators(userID, projectID uuid.UUID) ([]*models.ProjectCollaborator, error) {
+	if _, err := s.getMemberProject(userID, projectID); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.GetCollaborators(projectID)
 }
 
 // GetCollaborators gets all collaborators for a project
-func (s *ProjectServiceInterface) GetCollaborators(projectID uuid.UUID) ([]*models.ProjectCollaborator, error) {
+func (s *ProjectService) GetCollaborators(projectID uuid.UUID) ([]*models.ProjectCollaborator, error) {
 	return s.projectRepo.GetCollaborators(projectID)
 }
+
+// GetProjectActivity returns the activity feed of a project the user is a member of, newest
+// first. When since is set, only events that happened after it are returned, oldest first.
+func (s *ProjectService) GetProjectActivity(userID, projectID uuid.UUID, since *time.Time, limit, offset int) ([]*models.ActivityEvent, error) {
+	if _, err := s.getMemberProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.projectRepo.GetActivity(projectID, since, limit, offset)
+}
+
+// largestFilesInUsage is the number of files listed in a storage usage report
+const largestFilesInUsage = 10
+
+// GetProjectStorageUsage reports the disk space used by a project's files to one of its members
+func (s *ProjectService) GetProjectStorageUsage(userID, projectID uuid.UUID) (*models.StorageUsage, error) {
+	if _, err := s.getMemberProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.projectRepo.GetStorageUsage(projectID, largestFilesInUsage)
+}
+
+// ListBranches lists the branches of a project to one of its members, default branch
+// first, each with its file count and when it or one of its files last changed
+func (s *ProjectService) ListBranches(userID, projectID uuid.UUID) ([]*models.BranchSummary, error) {
+	if _, err := s.getMemberProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	branches, err := s.branchRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.fileRepo.CountByBranch(projectID)
+	if err != nil {
+		return nil, err
+	}
+	statsByBranch := make(map[uuid.UUID]models.BranchFileStats, len(stats))
+	for _, stat := range stats {
+		statsByBranch[stat.BranchID] = stat
+	}
+
+	summaries := make([]*models.BranchSummary, 0, len(branches))
+	for _, branch := range branches {
+		summary := &models.BranchSummary{Branch: *branch, LastUpdatedAt: branch.UpdatedAt}
+		if stat, ok := statsByBranch[branch.ID]; ok {
+			summary.FileCount = stat.FileCount
+			if stat.LastUpdatedAt.After(summary.LastUpdatedAt) {
+				summary.LastUpdatedAt = stat.LastUpdatedAt
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// GetBranchFiles lists the files on one branch of a project, with their audio metadata.
+// A branch that belongs to another project is reported as not found.
+func (s *ProjectService) GetBranchFiles(userID, projectID, branchID uuid.UUID) ([]*models.File, error) {
+	if _, err := s.getMemberProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getProjectBranch(projectID, branchID); err != nil {
+		return nil, err
+	}
+
+	return s.fileRepo.GetByBranchID(branchID)
+}
+
+// maxBranchNameLength bounds the length of a branch name
+const maxBranchNameLength = 100
+
+// RenameBranch renames a branch of a project. Renaming the default branch, the one named
+// by the project's CurrentBranch, renames that as well. Only the owner and admins of the
+// project may rename branches; a name another branch of the project has is ErrConflict.
+func (s *ProjectService) RenameBranch(userID, projectID, branchID uuid.UUID, name string) (*models.Branch, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxBranchNameLength {
+		return nil, &FieldError{Field: "name", Message: fmt.Sprintf("must be between 1 and %d characters", maxBranchNameLength)}
+	}
+
+	project, err := s.getManageableProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := s.getProjectBranch(projectID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if branch.Name == name {
+		return branch, nil
+	}
+
+	branches, err := s.branchRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range branches {
+		if other.ID != branch.ID && other.Name == name {
+			return nil, fmt.Errorf("%w: a branch named %q already exists", ErrConflict, name)
+		}
+	}
+
+	oldName := branch.Name
+	branch.Name = name
+	if err := s.branchRepo.Update(branch); err != nil {
+		return nil, err
+	}
+
+	if branch.IsDefault || project.CurrentBranch == oldName {
+		project.CurrentBranch = name
+		if err := s.projectRepo.Update(project); err != nil {
+			return nil, err
+		}
+	}
+	return branch, nil
+}
+
+// MergeBranch merges the files of a source branch into a target branch of the same project.
+// Files only on the source are copied; for files on both, the newest wins unless both
+// changed since the source branch was created, in which case the file is reported as a
+// conflict and left untouched. All changes are written in one transaction. Members other
+// than viewers may merge branches.
+func (s *ProjectService) MergeBranch(userID, projectID, sourceID, targetID uuid.UUID) (*models.MergeResult, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: cannot merge a branch into itself", ErrInvalid)
+	}
+
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanEditFiles(userID, project); err != nil {
+		return nil, err
+	}
+
+	source, err := s.getProjectBranch(projectID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getProjectBranch(projectID, targetID); err != nil {
+		return nil, err
+	}
+
+	sourceFiles, err := s.fileRepo.GetByBranchID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	targetFiles, err := s.fileRepo.GetByBranchID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	targetByPath := make(map[string]*models.File, len(targetFiles))
+	for _, file := range targetFiles {
+		targetByPath[file.Path] = file
+	}
+
+	result := &models.MergeResult{
+		SourceBranchID: sourceID,
+		TargetBranchID: targetID,
+		Added:          []string{},
+		Updated:        []string{},
+		Unchanged:      []string{},
+		Conflicts:      []string{},
+	}
+	var created, updated []*models.File
+
+	for _, file := range sourceFiles {
+		target, exists := targetByPath[file.Path]
+		switch {
+		case !exists:
+			created = append(created, &models.File{
+				ProjectID:    projectID,
+				BranchID:     targetID,
+				Name:         file.Name,
+				OriginalName: file.OriginalName,
+				Path:         file.Path,
+				FileType:     file.FileType,
+				MimeType:     file.MimeType,
+				Size:         file.Size,
+				Checksum:     file.Checksum,
+				StoragePath:  file.StoragePath,
+				IsPublic:     file.IsPublic,
+				UploadedBy:   file.UploadedBy,
+			})
+			result.Added = append(result.Added, file.Path)
+		case target.Checksum == file.Checksum:
+			result.Unchanged = append(result.Unchanged, file.Path)
+		case target.UpdatedAt.After(source.CreatedAt) && file.UpdatedAt.After(source.CreatedAt):
+			result.Conflicts = append(result.Conflicts, file.Path)
+		case file.UpdatedAt.After(target.UpdatedAt):
+			target.Size = file.Size
+			target.Checksum = file.Checksum
+			target.StoragePath = file.StoragePath
+			target.MimeType = file.MimeType
+			target.AudioMetadata = nil
+			updated = append(updated, target)
+			result.Updated = append(result.Updated, file.Path)
+		default:
+			result.Unchanged = append(result.Unchanged, file.Path)
+		}
+	}
+
+	if err := s.fileRepo.SaveBatch(created, updated); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getProjectBranch loads a branch of the given project, translating a missing record
+// or a branch of another project into ErrNotFound
+func (s *ProjectService) getProjectBranch(projectID, branchID uuid.UUID) (*models.Branch, error) {
+	branch, err := s.branchRepo.GetByID(branchID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if branch.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	return branch, nil
+}
+
+// getMemberProject loads a project the user is a member of. Non-members get ErrNotFound for
+// private projects, so their existence isn't revealed, and ErrForbidden for public ones.
+func (s *ProjectService) getMemberProject(userID, projectID uuid.UUID) (*models.Project, error) {
+	project, err := s.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.CanAccessProject(userID, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// getProject loads a project, translating a missing record into ErrNotFound
+func (s *ProjectService) getProject(projectID uuid.UUID) (*models.Project, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return project, err
+}
+
+// notifyUser sends a project notification to a user in the background.
+// Delivery failures are logged and never surface to the caller.
+func (s *ProjectService) notifyUser(projectID, userID uuid.UUID, template NotificationTemplate, role string) {
+	go func() {
+		fields := logrus.Fields{
+			"template":   template,
+			"project_id": projectID,
+			"user_id":    userID,
+		}
+
+		project, err := s.projectRepo.GetByID(projectID)
+		if err != nil {
+			logger.WithFields(fields).Errorf("Failed to load project for notification: %v", err)
+			return
+		}
+
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			logger.WithFields(fields).Errorf("Failed to load recipient for notification: %v", err)
+			return
+		}
+
+		notification := Notification{
+			To:       user.Email,
+			Template: template,
+			Data: map[string]string{
+				"ProjectName":   project.Name,
+				"RecipientName": user.GetFullName(),
+				"Role":          role,
+			},
+		}
+
+		if err := s.notifier.Notify(notification); err != nil {
+			logger.WithFields(fields).Errorf("Failed to deliver notification: %v", err)
+		}
+	}()
+}
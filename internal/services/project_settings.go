@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	"collabhub-music-backend/internal/models"
+)
+
+// Accepted ranges for numeric project settings; zero means the setting isn't set
+const (
+	minSampleRate = 8000
+	maxSampleRate = 192000
+	minTempo      = 20
+	maxTempo      = 300
+)
+
+var bitDepths = map[int]bool{16: true, 24: true, 32: true}
+
+var timeSignatures = map[string]bool{
+	"2/4": true, "3/4": true, "4/4": true, "5/4": true, "7/4": true,
+	"3/8": true, "5/8": true, "6/8": true, "7/8": true, "9/8": true, "12/8": true,
+}
+
+// musicalKeys holds every accepted key, written as the tonic followed by "major" or "minor",
+// e.g. "F# minor"
+var musicalKeys = func() map[string]bool {
+	keys := make(map[string]bool, len(models.MusicalTonics)*2)
+	for _, tonic := range models.MusicalTonics {
+		keys[tonic+" major"] = true
+		keys[tonic+" minor"] = true
+	}
+	return keys
+}()
+
+// validateProjectSettings checks every set field of the settings against its accepted values
+func validateProjectSettings(settings models.ProjectSettings) error {
+	if settings.SampleRate != 0 && (settings.SampleRate < minSampleRate || settings.SampleRate > maxSampleRate) {
+		return &FieldError{Field: "sample_rate", Message: fmt.Sprintf("must be between %d and %d", minSampleRate, maxSampleRate)}
+	}
+	if settings.BitDepth != 0 && !bitDepths[settings.BitDepth] {
+		return &FieldError{Field: "bit_depth", Message: "must be one of 16, 24, 32"}
+	}
+	if settings.Tempo != 0 && (settings.Tempo < minTempo || settings.Tempo > maxTempo) {
+		return &FieldError{Field: "tempo", Message: fmt.Sprintf("must be between %d and %d", minTempo, maxTempo)}
+	}
+	if settings.TimeSignature != "" && !timeSignatures[settings.TimeSignature] {
+		return &FieldError{Field: "time_signature", Message: "is not a supported time signature"}
+	}
+	if settings.Key != "" && !musicalKeys[settings.Key] {
+		return &FieldError{Field: "key", Message: `must be a tonic followed by "major" or "minor", e.g. "A minor"`}
+	}
+	if settings.WebhookURL != "" {
+		u, err := url.Parse(settings.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &FieldError{Field: "webhook_url", Message: "must be an absolute http or https URL"}
+		}
+		if isInternalHost(u.Hostname()) {
+			return &FieldError{Field: "webhook_url", Message: "must not point at a local or private address"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+
+    "collabhub-music-backend/internal/models"
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+)
+
+// ProjectStorage lists what a project keeps on disk: its extracted files and the archives
+// extracted into it. TotalBytes covers both, FileCount only the extracted files. It
+// returns ErrNotFound for projects that aren't saved.
+func (s *ZipService) ProjectStorage(projectID uuid.UUID) (*models.ProjectStorage, error) {
+    storage := &models.ProjectStorage{
+        ProjectID: projectID,
+        Paths:     []string{},
+        Archives:  []string{},
+    }
+
+    if s.projects != nil {
+        project, err := s.projects.GetByID(projectID)
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, ErrNotFound
+        }
+        if err != nil {
+            return nil, err
+        }
+        storage.StoragePurged = project.StoragePurged
+    }
+
+    projectPath := s.ProjectPath(projectID)
+    err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            // Nothing extracted yet, or removed while walking
+            if os.IsNotExist(err) {
+                return nil
+            }
+            return err
+        }
+        if info.IsDir() {
+            return nil
+        }
+
+        relPath, err := filepath.Rel(projectPath, path)
+        if err != nil {
+            return err
+        }
+        storage.Paths = append(storage.Paths, filepath.ToSlash(relPath))
+        storage.FileCount++
+        storage.TotalBytes += info.Size()
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if s.uploads != nil {
+        uploads, err := s.uploads.GetByProjectID(projectID)
+        if err != nil {
+            return nil, err
+        }
+        for _, upload := range uploads {
+            info, err := os.Stat(upload.Path)
+            if os.IsNotExist(err) {
+                continue
+            }
+            if err != nil {
+                return nil, err
+            }
+            storage.Archives = append(storage.Archives, upload.Path)
+            storage.TotalBytes += info.Size()
+        }
+    }
+
+    return storage, nil
+}
+
+// PurgeProjectStorage removes a project's extracted files and the archives extracted into
+// it from disk and flags the project as purged. Its rows, such as files and uploads, are
+// kept. It returns what was removed, or ErrConflict while one of the archives is being
+// extracted.
+func (s *ZipService) PurgeProjectStorage(projectID uuid.UUID) (*models.ProjectStorage, error) {
+    storage, err := s.ProjectStorage(projectID)
+    if err != nil {
+        return nil, err
+    }
+
+    // Hold the archives' extraction marks so none is extracted into the project meanwhile
+    for _, archive := range storage.Archives {
+        done, err := s.BeginExtraction(archive)
+        if err != nil {
+            return nil, err
+        }
+        defer done()
+    }
+
+    if err := os.RemoveAll(s.ProjectPath(projectID)); err != nil {
+        return nil, err
+    }
+    for _, archive := range storage.Archives {
+        if err := os.Remove(archive); err != nil && !os.IsNotExist(err) {
+            return nil, err
+        }
+    }
+
+    if s.projects != nil {
+        if err := s.projects.MarkStoragePurged(projectID); err != nil {
+            return nil, err
+        }
+    }
+    storage.StoragePurged = true
+    return storage, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageLayout decides how uploaded archives and extracted projects are arranged on disk
+type StorageLayout string
+
+const (
+	// StorageLayoutFlat keeps every archive in one directory and every project directly
+	// under the extraction root
+	StorageLayoutFlat StorageLayout = "flat"
+
+	// StorageLayoutSharded stores archives under YYYY/MM/DD of their upload date and
+	// projects under two levels named after the first bytes of their ID (ab/cd/abcd...)
+	StorageLayoutSharded StorageLayout = "sharded"
+)
+
+// ParseStorageLayout validates a layout name; an empty name selects the flat layout
+func ParseStorageLayout(name string) (StorageLayout, error) {
+	switch StorageLayout(name) {
+	case "", StorageLayoutFlat:
+		return StorageLayoutFlat, nil
+	case StorageLayoutSharded:
+		return StorageLayoutSharded, nil
+	default:
+		return "", fmt.Errorf("unknown storage layout %q, expected %q or %q", name, StorageLayoutFlat, StorageLayoutSharded)
+	}
+}
+
+// UploadDir returns the directory an archive uploaded at the given time is stored in
+func (l StorageLayout) UploadDir(root string, uploadedAt time.Time) string {
+	if l == StorageLayoutSharded {
+		return filepath.Join(root, uploadedAt.UTC().Format("2006"), uploadedAt.UTC().Format("01"), uploadedAt.UTC().Format("02"))
+	}
+	return root
+}
+
+// ProjectDir returns the directory a project's files are extracted to
+func (l StorageLayout) ProjectDir(root string, projectID uuid.UUID) string {
+	id := projectID.String()
+	if l == StorageLayoutSharded {
+		return filepath.Join(root, id[0:2], id[2:4], id)
+	}
+	return filepath.Join(root, id)
+}
+
+// uploadGlob matches the archive stored for an upload ID anywhere the layout may have put it
+func (l StorageLayout) uploadGlob(root string, fileID uuid.UUID) string {
+	if l == StorageLayoutSharded {
+		return filepath.Join(root, "*", "*", "*", fileID.String()+"_*.zip")
+	}
+	return filepath.Join(root, fileID.String()+"_*.zip")
+}
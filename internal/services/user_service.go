@@ -1,49 +1,363 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"collabhub-music-backend/internal/models"
 	"collabhub-music-backend/internal/repository"
+	"collabhub-music-backend/pkg/logger"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // UserService provides user-related business logic
-type UserServiceInterface struct {
-	userRepo repository.UserRepositoryInterface
+type UserService struct {
+	userRepo        repository.UserRepositoryInterface
+	keycloakService *KeycloakService
 }
 
 // NewUserService creates a new instance of UserService
-func NewUserService(userRepo repository.UserRepositoryInterface) *UserServiceInterface {
-	return &UserServiceInterface{
-		userRepo: userRepo,
+func NewUserService(userRepo repository.UserRepositoryInterface, keycloakService *KeycloakService) *UserService {
+	return &UserService{
+		userRepo:        userRepo,
+		keycloakService: keycloakService,
 	}
 }
 
+// NormalizeUsername returns the canonical form usernames are stored and looked up in
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
 // CreateUser creates a new user
-func (s *UserServiceInterface) CreateUser(user *models.User) error {
+func (s *UserService) CreateUser(user *models.User) error {
+	user.Username = NormalizeUsername(user.Username)
 	return s.userRepo.Create(user)
 }
 
 // GetUserByID retrieves a user by ID
-func (s *UserServiceInterface) GetUserByID(id uuid.UUID) (*models.User, error) {
+func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return s.userRepo.GetByID(id)
 }
 
 // GetUserByEmail retrieves a user by email
-func (s *UserServiceInterface) GetUserByEmail(email string) (*models.User, error) {
+func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	return s.userRepo.GetByEmail(email)
 }
 
 // GetUserByUsername retrieves a user by username
-func (s *UserServiceInterface) GetUserByUsername(username string) (*models.User, error) {
+func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 	return s.userRepo.GetByUsername(username)
 }
 
+// GetPublicProfile looks up the public profile of a user by username, normalized the same
+// way as on registration. Deactivated users are reported as ErrNotFound, like unknown ones.
+func (s *UserService) GetPublicProfile(username string) (*models.PublicUserProfile, error) {
+	username = NormalizeUsername(username)
+	if username == "" {
+		return nil, ErrNotFound
+	}
+
+	user, err := s.userRepo.GetByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanSignIn() {
+		return nil, ErrNotFound
+	}
+
+	return &models.PublicUserProfile{
+		ID:        user.ID,
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Avatar:    user.Avatar,
+	}, nil
+}
+
+// IsUsernameAvailable reports whether no user holds the username.
+// The username is normalized the same way as on registration before the lookup.
+func (s *UserService) IsUsernameAvailable(username string) (bool, error) {
+	username = NormalizeUsername(username)
+	if username == "" {
+		return false, &FieldError{Field: "username", Message: "is required"}
+	}
+
+	_, err := s.userRepo.GetByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
 // UpdateUser updates a user
-func (s *UserServiceInterface) UpdateUser(user *models.User) error {
+func (s *UserService) UpdateUser(user *models.User) error {
 	return s.userRepo.Update(user)
 }
 
+// ErrIdentityProvider is returned when Keycloak rejects or fails a change made to a user
+var ErrIdentityProvider = errors.New("identity provider rejected the change")
+
+// UpdateProfile applies a profile update locally and in Keycloak, so both systems keep the
+// same email and name. If Keycloak rejects the change, the local update is rolled back.
+// Changing the email marks it unverified and asks Keycloak to verify the new address.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uuid.UUID, update *models.UserProfileUpdate) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	previous := *user
+
+	emailChanged := update.Email != "" && !strings.EqualFold(update.Email, user.Email)
+	if emailChanged {
+		user.Email = update.Email
+		user.EmailVerified = false
+	}
+	if update.FirstName != "" {
+		user.FirstName = update.FirstName
+	}
+	if update.LastName != "" {
+		user.LastName = update.LastName
+	}
+	if update.Avatar != "" {
+		user.Avatar = update.Avatar
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if user.KeycloakID == "" {
+		return user, nil
+	}
+
+	keycloakUser := keycloakRepresentation(user)
+	if emailChanged {
+		keycloakUser.RequiredActions = []string{"VERIFY_EMAIL"}
+	}
+
+	if err := s.keycloakService.UpdateUser(ctx, user.KeycloakID, keycloakUser); err != nil {
+		if rollbackErr := s.userRepo.Update(&previous); rollbackErr != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id": userID,
+				"error":   rollbackErr,
+			}).Error("Failed to roll back profile update after Keycloak rejected it")
+		}
+		return nil, fmt.Errorf("%w: %v", ErrIdentityProvider, err)
+	}
+
+	return user, nil
+}
+
+// SetAccountActive deactivates or reactivates a user both locally and in Keycloak, where
+// Enabled follows IsActive. Only the user themselves or an admin may change it. If Keycloak
+// rejects the change the local user is rolled back, so the two never disagree.
+func (s *UserService) SetAccountActive(ctx context.Context, actorID, userID uuid.UUID, isAdmin, active bool) (*models.User, error) {
+	if actorID != userID && !isAdmin {
+		return nil, ErrForbidden
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive == active {
+		return user, nil
+	}
+	previous := *user
+
+	user.IsActive = active
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if user.KeycloakID == "" {
+		return user, nil
+	}
+
+	if err := s.keycloakService.UpdateUser(ctx, user.KeycloakID, keycloakRepresentation(user)); err != nil {
+		if rollbackErr := s.userRepo.Update(&previous); rollbackErr != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id": userID,
+				"error":   rollbackErr,
+			}).Error("Failed to roll back account status after Keycloak rejected it")
+		}
+		return nil, fmt.Errorf("%w: %v", ErrIdentityProvider, err)
+	}
+
+	return user, nil
+}
+
+// keycloakRepresentation returns the Keycloak user matching a local user. Keycloak
+// replaces these fields on update, so they are always sent together.
+func keycloakRepresentation(user *models.User) *KeycloakUser {
+	return &KeycloakUser{
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Enabled:       user.IsActive,
+	}
+}
+
+// DeleteAccount deletes a user both locally and in Keycloak, so the account can no longer
+// log in. Only the user themselves or an admin may delete it. The local soft delete happens
+// first since it can be undone: if Keycloak then fails, the local user is restored.
+func (s *UserService) DeleteAccount(ctx context.Context, actorID, userID uuid.UUID, isAdmin bool) error {
+	if actorID != userID && !isAdmin {
+		return ErrForbidden
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(user.ID); err != nil {
+		return err
+	}
+
+	if user.KeycloakID == "" {
+		return nil
+	}
+
+	if err := s.keycloakService.DeleteUser(ctx, user.KeycloakID); err != nil {
+		if restoreErr := s.userRepo.Restore(user.ID); restoreErr != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id": userID,
+				"error":   restoreErr,
+			}).Error("Failed to restore user after Keycloak rejected the deletion")
+		}
+		return fmt.Errorf("%w: %v", ErrIdentityProvider, err)
+	}
+
+	return nil
+}
+
 // DeleteUser deletes a user
-func (s *UserServiceInterface) DeleteUser(id uuid.UUID) error {
+func (s *UserService) DeleteUser(id uuid.UUID) error {
 	return s.userRepo.Delete(id)
 }
+
+// SearchUsers searches users by partial username or email for administrators
+func (s *UserService) SearchUsers(query string, includeDeleted bool, limit, offset int) ([]*models.User, int64, error) {
+	return s.userRepo.Search(query, includeDeleted, limit, offset)
+}
+
+// ErrAccountInactive is returned when a deactivated or deleted user signs in
+var ErrAccountInactive = errors.New("account is deactivated or deleted")
+
+// Login signs a user in with Keycloak and resolves their local user, creating it on first
+// login. Deactivated and deleted users get ErrAccountInactive rather than tokens.
+func (s *UserService) Login(ctx context.Context, username, password string) (*models.LoginResponse, error) {
+	tokens, err := s.keycloakService.Login(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.SyncUserFromKeycloak(ctx, tokens.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanSignIn() {
+		return nil, ErrAccountInactive
+	}
+
+	return &models.LoginResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		TokenType:    tokens.TokenType,
+		User:         user,
+	}, nil
+}
+
+// SyncUserFromKeycloak resolves the local user for a Keycloak token, creating it on first login,
+// and records the login time. A local user without a Keycloak account yet is linked on first
+// login when Keycloak has verified that its email is theirs. Deactivated and deleted users are
+// returned as they are, without recording a login; callers must check User.CanSignIn.
+func (s *UserService) SyncUserFromKeycloak(ctx context.Context, token string) (*models.User, error) {
+	info, err := s.keycloakService.GetUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if info.ID == "" {
+		return nil, fmt.Errorf("user info is missing the subject claim")
+	}
+
+	user, err := s.userRepo.GetByKeycloakID(info.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.linkLocalUser(info)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user = &models.User{
+			KeycloakID:    info.ID,
+			Email:         info.Email,
+			EmailVerified: info.EmailVerified,
+			Username:      NormalizeUsername(info.Username),
+			FirstName:     info.FirstName,
+			LastName:      info.LastName,
+			IsActive:      true,
+		}
+		if err := s.userRepo.Create(user); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if !user.CanSignIn() {
+		return user, nil
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdateLastLogin(user.ID, now); err != nil {
+		return nil, err
+	}
+	user.LastLoginAt = &now
+
+	return user, nil
+}
+
+// linkLocalUser attaches a Keycloak account to the local user with the same verified email
+// that has no Keycloak account yet. It returns gorm.ErrRecordNotFound when there is none.
+func (s *UserService) linkLocalUser(info *KeycloakUser) (*models.User, error) {
+	if !info.EmailVerified || info.Email == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	user, err := s.userRepo.GetByEmail(info.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user.KeycloakID != "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	user.KeycloakID = info.ID
+	user.EmailVerified = true
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"collabhub-music-backend/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook event types
+const (
+	WebhookEventExtractionCompleted = "extraction.completed"
+)
+
+// Webhook request headers. The signature is "sha256=" followed by the hex HMAC-SHA256 of
+// the request body, keyed with the webhook secret.
+const (
+	WebhookEventHeader     = "X-CollabHub-Event"
+	WebhookSignatureHeader = "X-CollabHub-Signature"
+)
+
+// DefaultWebhookTimeout bounds a single webhook delivery
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookEvent is the body posted to a project's webhook URL
+type WebhookEvent struct {
+	Event      string      `json:"event"`
+	ProjectID  uuid.UUID   `json:"project_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// ExtractionCompletedData is the data of an extraction.completed event
+type ExtractionCompletedData struct {
+	JobID        uuid.UUID `json:"job_id"`
+	Success      bool      `json:"success"`
+	TotalFiles   int       `json:"total_files"`
+	AudioFiles   int       `json:"audio_files"`
+	SkippedFiles int       `json:"skipped_files"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// ErrWebhookAddressBlocked is returned when a webhook URL points at a loopback, link-local,
+// private or otherwise internal address
+var ErrWebhookAddressBlocked = errors.New("webhook address is not publicly routable")
+
+// WebhookSender posts signed events to the webhook URL set in a project's settings
+type WebhookSender struct {
+	projectRepo repository.ProjectRepositoryInterface
+	secret      []byte
+	client      *http.Client
+}
+
+// WebhookSenderConfig holds the repository and delivery settings used by the webhook sender
+type WebhookSenderConfig struct {
+	Projects repository.ProjectRepositoryInterface
+	Secret   string
+	Timeout  time.Duration // a single delivery, DefaultWebhookTimeout when zero
+
+	// AllowPrivateNetworks lets webhooks reach loopback, link-local and private addresses.
+	// It is off by default so that project members can't make the server call internal
+	// services; turn it on only for local development.
+	AllowPrivateNetworks bool
+}
+
+// NewWebhookSender creates a new instance of WebhookSender that only delivers to public
+// addresses; a zero timeout uses DefaultWebhookTimeout
+func NewWebhookSender(projectRepo repository.ProjectRepositoryInterface, secret string, timeout time.Duration) *WebhookSender {
+	return NewWebhookSenderWithConfig(WebhookSenderConfig{
+		Projects: projectRepo,
+		Secret:   secret,
+		Timeout:  timeout,
+	})
+}
+
+// NewWebhookSenderWithConfig creates a new instance of WebhookSender. Redirects are never
+// followed, and unless private networks are allowed every connection is checked after DNS
+// resolution, so a hostname can't be pointed at an internal address after validation.
+func NewWebhookSenderWithConfig(cfg WebhookSenderConfig) *WebhookSender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultWebhookTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressBlocked, host)
+			}
+			return nil
+		}
+	}
+
+	return &WebhookSender{
+		projectRepo: cfg.Projects,
+		secret:      []byte(cfg.Secret),
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// No proxy, so that the dialer sees the receiver's address
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+					return dialer.DialContext(ctx, network, address)
+				},
+				TLSHandshakeTimeout: cfg.Timeout,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send delivers the event to its project's webhook. Projects without a webhook URL are
+// skipped. Without a secret nothing is sent, since receivers couldn't verify it. Any
+// response other than 2xx, including a redirect, is an error; deliveries are not retried.
+func (w *WebhookSender) Send(event WebhookEvent) error {
+	project, err := w.projectRepo.GetByID(event.ProjectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if project.Settings.WebhookURL == "" {
+		return nil
+	}
+	if len(w.secret) == 0 {
+		return errors.New("webhook secret is not configured")
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, project.Settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event.Event)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.secret, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// isInternalIP reports whether an address is loopback, link-local, private, unspecified or
+// multicast, none of which a webhook may be delivered to
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast()
+}
+
+// isInternalHost reports whether a URL host is obviously internal: localhost or an internal
+// IP literal. Hostnames are only checked when a webhook is delivered, after resolution.
+func isInternalHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && isInternalIP(ip)
+}
+
+// SignWebhookPayload returns the signature header value for a webhook body
+func SignWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "path/filepath"
+    "strings"
+
+    "collabhub-music-backend/internal/models"
+    "collabhub-music-backend/internal/repository"
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+)
+
+// SaveExtractedProject saves a project created from an extracted archive on a new default
+// branch, with a File for each extracted file and the AudioMetadata and a draft Track of
+// each audio file.
+// Tracks mirror the BPM, key, genre and duration of their file's metadata. A file whose
+// duration can't be decoded still gets a track, with zero duration and DurationUnknown
+// set. With a Transactor everything is saved in one transaction, so a failed step leaves
+// nothing behind. Without project storage nothing is saved.
+func (s *ZipService) SaveExtractedProject(ctx context.Context, project *models.Project, result *models.ZipExtractionResult) ([]*models.Track, error) {
+    if s.projects == nil || s.branches == nil || s.files == nil || s.tracks == nil {
+        return nil, nil
+    }
+
+    if s.transactor == nil {
+        return saveExtractedProject(&repository.Repositories{
+            Projects: s.projects,
+            Branches: s.branches,
+            Files:    s.files,
+            Tracks:   s.tracks,
+        }, project, result)
+    }
+
+    var tracks []*models.Track
+    err := s.transactor.WithTransaction(ctx, func(tx *gorm.DB) error {
+        var err error
+        tracks, err = saveExtractedProject(repository.NewRepositories(tx), project, result)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return tracks, nil
+}
+
+// saveExtractedProject does the work of SaveExtractedProject through repos
+func saveExtractedProject(repos *repository.Repositories, project *models.Project, result *models.ZipExtractionResult) ([]*models.Track, error) {
+    if err := repos.Projects.Create(project); err != nil {
+        return nil, err
+    }
+
+    branch := &models.Branch{
+        ID:        uuid.New(),
+        ProjectID: project.ID,
+        Name:      project.CurrentBranch,
+        IsDefault: true,
+        IsActive:  true,
+        CreatedBy: project.CreatedBy,
+    }
+    if branch.Name == "" {
+        branch.Name = "main"
+    }
+    if err := repos.Branches.Create(branch); err != nil {
+        return nil, err
+    }
+
+    files := make([]*models.File, 0, len(result.ExtractedFiles))
+    metadatas := make([]*models.AudioMetadata, 0, len(result.AudioFiles))
+    tracks := make([]*models.Track, 0, len(result.AudioFiles))
+    for _, info := range result.ExtractedFiles {
+        if !isExtractedFile(info) {
+            continue
+        }
+        file := newExtractedFile(project.ID, branch.ID, project.CreatedBy, result.ExtractedPath, info)
+        files = append(files, file)
+        if !info.IsAudioFile {
+            continue
+        }
+
+        metadata := newAudioMetadata(file.ID, readAudioInfo(file.StoragePath))
+        metadatas = append(metadatas, metadata)
+
+        track := &models.Track{
+            ID:        uuid.New(),
+            ProjectID: project.ID,
+            Name:      metadata.Title,
+            FileID:    &file.ID,
+            Status:    models.TrackStatusDraft,
+            CreatedBy: project.CreatedBy,
+        }
+        mirrorAudioMetadata(track, metadata)
+        tracks = append(tracks, track)
+    }
+
+    if err := repos.Files.SaveBatch(files, nil); err != nil {
+        return nil, err
+    }
+    for _, metadata := range metadatas {
+        if err := repos.Files.CreateAudioMetadata(metadata); err != nil {
+            return nil, err
+        }
+    }
+    if err := repos.Tracks.CreateBatch(tracks); err != nil {
+        return nil, err
+    }
+    return tracks, nil
+}
+
+// recordExtractedFiles saves the files extracted into an existing project as File rows on
+// its default branch, updating the rows of files that were extracted over, all in one
+// transaction. It does nothing without file storage or for projects that aren't saved
+// yet; SaveExtractedProject saves those along with their files.
+func (s *ZipService) recordExtractedFiles(projectID, uploadedBy uuid.UUID, result *models.ZipExtractionResult) error {
+    if s.projects == nil || s.branches == nil || s.files == nil {
+        return nil
+    }
+
+    project, err := s.projects.GetByID(projectID)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    branch, err := s.defaultBranch(projectID)
+    if errors.Is(err, ErrNotFound) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if uploadedBy == uuid.Nil {
+        uploadedBy = project.OwnerID
+    }
+
+    existing, err := s.files.GetByBranchID(branch.ID)
+    if err != nil {
+        return err
+    }
+    byPath := make(map[string]*models.File, len(existing))
+    for _, file := range existing {
+        byPath[file.Path] = file
+    }
+
+    var created, updated []*models.File
+    for _, info := range result.ExtractedFiles {
+        if !isExtractedFile(info) {
+            continue
+        }
+        fresh := newExtractedFile(projectID, branch.ID, uploadedBy, result.ExtractedPath, info)
+        file, ok := byPath[info.Path]
+        if !ok {
+            created = append(created, fresh)
+            continue
+        }
+        file.FileType = fresh.FileType
+        file.MimeType = fresh.MimeType
+        file.Size = fresh.Size
+        file.Checksum = fresh.Checksum
+        file.StoragePath = fresh.StoragePath
+        // Saving the file mustn't touch its metadata
+        file.AudioMetadata = nil
+        updated = append(updated, file)
+    }
+    if len(created) == 0 && len(updated) == 0 {
+        return nil
+    }
+    return s.files.SaveBatch(created, updated)
+}
+
+// savedExtractedFiles lists the File rows of a project's default branch as extracted files.
+// It lists nothing without file storage or a default branch.
+func (s *ZipService) savedExtractedFiles(projectID uuid.UUID) ([]models.ZipFileInfo, error) {
+    if s.branches == nil || s.files == nil {
+        return nil, nil
+    }
+
+    branch, err := s.defaultBranch(projectID)
+    if errors.Is(err, ErrNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    files, err := s.files.GetByBranchID(branch.ID)
+    if err != nil {
+        return nil, err
+    }
+
+    infos := make([]models.ZipFileInfo, 0, len(files))
+    for _, file := range files {
+        infos = append(infos, models.ZipFileInfo{
+            Name:        file.Name,
+            Path:        file.Path,
+            Size:        file.Size,
+            ContentType: file.MimeType,
+            IsAudioFile: file.FileType == "audio",
+            ModTime:     file.UpdatedAt,
+            Checksum:    file.Checksum,
+        })
+    }
+    return infos, nil
+}
+
+// defaultBranch returns the default branch of a project, or ErrNotFound
+func (s *ZipService) defaultBranch(projectID uuid.UUID) (*models.Branch, error) {
+    branches, err := s.branches.GetByProjectID(projectID)
+    if err != nil {
+        return nil, err
+    }
+    for _, branch := range branches {
+        if branch.IsDefault {
+            return branch, nil
+        }
+    }
+    return nil, ErrNotFound
+}
+
+// isExtractedFile reports whether an extracted entry is a file written to disk, rather
+// than a directory or a symbolic link
+func isExtractedFile(info models.ZipFileInfo) bool {
+    return !info.IsDirectory && info.Checksum != ""
+}
+
+// newExtractedFile builds the File row of a file extracted to extractedPath
+func newExtractedFile(projectID, branchID, uploadedBy uuid.UUID, extractedPath string, info models.ZipFileInfo) *models.File {
+    return &models.File{
+        ID:           uuid.New(),
+        ProjectID:    projectID,
+        BranchID:     branchID,
+        Name:         info.Name,
+        OriginalName: info.Name,
+        Path:         info.Path,
+        FileType:     fileTypeOf(strings.ToLower(filepath.Ext(info.Name))),
+        MimeType:     info.ContentType,
+        Size:         info.Size,
+        Checksum:     info.Checksum,
+        StoragePath:  filepath.Join(extractedPath, filepath.FromSlash(info.Path)),
+        UploadedBy:   uploadedBy,
+    }
+}
@@ -2,63 +2,543 @@ package services
 
 import (
     "archive/zip"
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
     "fmt"
     "io"
     "mime"
+    "net/http"
     "os"
     "path/filepath"
+    "sort"
     "strings"
+    "sync"
+    "time"
 
     "collabhub-music-backend/internal/models"
+    "collabhub-music-backend/internal/repository"
     "github.com/google/uuid"
+    "gorm.io/gorm"
 )
 
+// audioExtensions lists the file extensions treated as supported audio files
+var audioExtensions = map[string]bool{
+    ".mp3":  true,
+    ".wav":  true,
+    ".flac": true,
+    ".aac":  true,
+    ".ogg":  true,
+    ".m4a":  true,
+    ".wma":  true,
+}
+
+// maxUncompressedSize is the largest total uncompressed size accepted for an archive
+const maxUncompressedSize = 500 * 1024 * 1024 // 500MB
+
+// MaxMetadataBatchSize caps the number of files a single metadata request may ask for
+const MaxMetadataBatchSize = 100
+
+// ZIP signatures: a local file header starts every non-empty archive, while an
+// archive with no entries consists of the end of central directory record alone
+var (
+    zipLocalFileSignature = []byte("PK\x03\x04")
+    zipEmptySignature     = []byte("PK\x05\x06")
+)
+
+// Errors returned by CheckZipSignature, both wrapping ErrInvalid
+var (
+    ErrNotZipArchive   = fmt.Errorf("%w: not a valid ZIP archive", ErrInvalid)
+    ErrEmptyZipArchive = fmt.Errorf("%w: ZIP archive is empty", ErrInvalid)
+)
+
+// AudioFileLimitError reports that a project would exceed its audio file limit.
+// It wraps ErrInvalid.
+type AudioFileLimitError struct {
+    AudioFiles int
+    Limit      int
+}
+
+func (e *AudioFileLimitError) Error() string {
+    return fmt.Sprintf("project would have %d audio files, the limit is %d", e.AudioFiles, e.Limit)
+}
+
+func (e *AudioFileLimitError) Unwrap() error {
+    return ErrInvalid
+}
+
+// DefaultMaxZipEntries is the default maximum number of entries accepted in an archive
+const DefaultMaxZipEntries = 10000
+
+// Defaults for the extraction pool: how many archives are extracted at once across all
+// requests, and how long an extraction waits for a free slot before giving up
+const (
+    DefaultMaxConcurrentExtractions = 4
+    DefaultExtractionQueueTimeout   = 30 * time.Second
+)
+
+// ErrExtractionQueueFull is returned when no extraction slot frees up in time
+var ErrExtractionQueueFull = errors.New("too many extractions in progress")
+
+// Default limits on extracted paths: the number of directory levels in an entry name and
+// the length in bytes of the full destination path, kept well under filesystem limits
+const (
+    DefaultMaxPathDepth  = 32
+    DefaultMaxPathLength = 1024
+)
+
+// ZipServiceConfig holds the paths and limits used by the ZIP service
+type ZipServiceConfig struct {
+    UploadPath  string
+    ExtractPath string
+    Layout      StorageLayout // arrangement of archives and projects on disk, flat by default
+    MaxEntries  int // maximum number of entries (files and folders) per archive
+
+    // MaxPathDepth and MaxPathLength bound the extracted paths; longer entries are skipped
+    MaxPathDepth  int // directory levels in an entry name
+    MaxPathLength int // bytes in the full destination path
+
+    // AllowSymlinks recreates symbolic links whose target stays inside the project directory.
+    // By default every symlink entry is skipped.
+    AllowSymlinks bool
+
+    // SkipHidden leaves out entries with a path component starting with a dot, such as
+    // .git/config or .cache/, when validating and extracting. NewZipService turns it on.
+    SkipHidden bool
+
+    // VerifyCRC reads every entry in full when validating and compares it against its CRC32,
+    // so corrupted archives are rejected before extraction. It costs a read of the whole
+    // archive, so it is off by default.
+    VerifyCRC bool
+
+    // MaxConcurrentExtractions caps the archives extracted at once; further extractions wait
+    // up to ExtractionQueueTimeout for a slot
+    MaxConcurrentExtractions int
+    ExtractionQueueTimeout   time.Duration
+
+    // MaxAudioFilesPerProject caps the audio files a project may hold, 0 for no limit
+    MaxAudioFilesPerProject int
+
+    // Uploads records who uploaded each archive. Without it uploads aren't
+    // tracked and any caller may delete an archive.
+    Uploads repository.FileUploadRepositoryInterface
+
+    // Projects, Branches, Files and Tracks save projects created from an archive, with a
+    // File and a Track for each extracted audio file. Without them such projects are
+    // extracted but not saved. Projects is also needed to check project membership before
+    // extracted files are listed, read or removed; those calls fail without it.
+    Projects repository.ProjectRepositoryInterface
+    Branches repository.BranchRepositoryInterface
+    Files    repository.FileRepositoryInterface
+    Tracks   repository.TrackRepositoryInterface
+
+    // Transactor saves such projects atomically, writing through repositories created on
+    // its transaction instead of the ones above. Without it each step is saved on its own.
+    Transactor repository.Transactor
+}
+
 // ZipService handles ZIP file operations
 type ZipService struct {
-    uploadPath string
-    extractPath string
+    uploadPath    string
+    extractPath   string
+    layout        StorageLayout
+    maxEntries    int
+    maxPathDepth  int
+    maxPathLength int
+    allowSymlinks bool
+    skipHidden    bool
+    verifyCRC     bool
+    maxAudioFiles int
+    uploads       repository.FileUploadRepositoryInterface
+    projects      repository.ProjectRepositoryInterface
+    branches      repository.BranchRepositoryInterface
+    files         repository.FileRepositoryInterface
+    tracks        repository.TrackRepositoryInterface
+    transactor    repository.Transactor
+    policy        *PolicyService
+
+    // extracting holds the archives currently being extracted
+    mu         sync.Mutex
+    extracting map[string]bool
+
+    // extractionSlots holds one token per running extraction
+    extractionSlots chan struct{}
+    queueTimeout    time.Duration
+
+    // validations caches ValidateZip results by archive path until the archive changes
+    validationMu sync.Mutex
+    validations  map[string]cachedValidation
+}
+
+// maxCachedValidations bounds the validation cache; it is emptied when full
+const maxCachedValidations = 1024
+
+// cachedValidation is the validation result of an archive as it was at the given size
+// and modification time
+type cachedValidation struct {
+    size    int64
+    modTime time.Time
+    result  *models.ZipValidationResult
 }
 
-// NewZipService creates a new ZIP service
+// NewZipService creates a new ZIP service with the default limits
 func NewZipService(uploadPath, extractPath string) *ZipService {
+    return NewZipServiceWithConfig(ZipServiceConfig{
+        UploadPath:  uploadPath,
+        ExtractPath: extractPath,
+        SkipHidden:  true,
+    })
+}
+
+// NewZipServiceWithConfig creates a new ZIP service; zero limits fall back to the defaults
+func NewZipServiceWithConfig(cfg ZipServiceConfig) *ZipService {
     // Ensure directories exist
-    os.MkdirAll(uploadPath, 0755)
-    os.MkdirAll(extractPath, 0755)
-    
+    os.MkdirAll(cfg.UploadPath, 0755)
+    os.MkdirAll(cfg.ExtractPath, 0755)
+
+    if cfg.MaxEntries <= 0 {
+        cfg.MaxEntries = DefaultMaxZipEntries
+    }
+    if cfg.MaxPathDepth <= 0 {
+        cfg.MaxPathDepth = DefaultMaxPathDepth
+    }
+    if cfg.MaxPathLength <= 0 {
+        cfg.MaxPathLength = DefaultMaxPathLength
+    }
+    if cfg.MaxConcurrentExtractions <= 0 {
+        cfg.MaxConcurrentExtractions = DefaultMaxConcurrentExtractions
+    }
+    if cfg.ExtractionQueueTimeout <= 0 {
+        cfg.ExtractionQueueTimeout = DefaultExtractionQueueTimeout
+    }
+    if cfg.Layout == "" {
+        cfg.Layout = StorageLayoutFlat
+    }
+
+    var policy *PolicyService
+    if cfg.Projects != nil {
+        policy = NewPolicyService(cfg.Projects, nil)
+    }
+
     return &ZipService{
-        uploadPath:  uploadPath,
-        extractPath: extractPath,
+        uploadPath:    cfg.UploadPath,
+        extractPath:   cfg.ExtractPath,
+        layout:        cfg.Layout,
+        maxEntries:    cfg.MaxEntries,
+        maxPathDepth:  cfg.MaxPathDepth,
+        maxPathLength: cfg.MaxPathLength,
+        allowSymlinks: cfg.AllowSymlinks,
+        skipHidden:    cfg.SkipHidden,
+        verifyCRC:     cfg.VerifyCRC,
+        maxAudioFiles: cfg.MaxAudioFilesPerProject,
+        uploads:       cfg.Uploads,
+        projects:      cfg.Projects,
+        branches:      cfg.Branches,
+        files:         cfg.Files,
+        tracks:        cfg.Tracks,
+        transactor:    cfg.Transactor,
+        policy:        policy,
+        extracting:    make(map[string]bool),
+        validations:   make(map[string]cachedValidation),
+
+        extractionSlots: make(chan struct{}, cfg.MaxConcurrentExtractions),
+        queueTimeout:    cfg.ExtractionQueueTimeout,
+    }
+}
+
+// CheckAudioFileLimit returns an *AudioFileLimitError if adding audio files to a project
+// would take it over the configured limit. Audio files already extracted for the project
+// count towards the limit.
+func (s *ZipService) CheckAudioFileLimit(projectID uuid.UUID, adding int) error {
+    if s.maxAudioFiles <= 0 {
+        return nil
+    }
+
+    existing := 0
+    files, err := s.extractedFiles(projectID)
+    if err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    for _, file := range files {
+        if file.IsAudioFile {
+            existing++
+        }
     }
+
+    if existing+adding > s.maxAudioFiles {
+        return &AudioFileLimitError{AudioFiles: existing + adding, Limit: s.maxAudioFiles}
+    }
+    return nil
+}
+
+// Capabilities reports the audio formats and archive limits this service enforces.
+// The upload size limit is enforced by the handlers and left for them to fill in.
+func (s *ZipService) Capabilities() *models.UploadCapabilities {
+    extensions := make([]string, 0, len(audioExtensions))
+    for ext := range audioExtensions {
+        extensions = append(extensions, ext)
+    }
+    sort.Strings(extensions)
+
+    return &models.UploadCapabilities{
+        AudioExtensions:         extensions,
+        MaxUncompressedSize:     maxUncompressedSize,
+        MaxArchiveEntries:       s.maxEntries,
+        MaxAudioFilesPerProject: s.maxAudioFiles,
+    }
+}
+
+// NewUploadPath returns where an archive uploaded now should be stored
+func (s *ZipService) NewUploadPath(filename string) string {
+    return filepath.Join(s.layout.UploadDir(filepath.Join(s.uploadPath, "zips"), time.Now()), filename)
 }
 
-// ValidateZip validates a ZIP file and returns information about its contents
+// CheckStorage creates the upload and extraction directories if needed and verifies that
+// they are writable, so a misconfigured volume is reported at startup instead of on the
+// first upload
+func (s *ZipService) CheckStorage() error {
+    for _, dir := range []string{filepath.Join(s.uploadPath, "zips"), s.extractPath} {
+        if err := os.MkdirAll(dir, 0755); err != nil {
+            return fmt.Errorf("failed to create storage directory: %w", err)
+        }
+
+        probe, err := os.CreateTemp(dir, ".write-check-*")
+        if err != nil {
+            return fmt.Errorf("storage directory %s is not writable: %w", dir, err)
+        }
+        probe.Close()
+        if err := os.Remove(probe.Name()); err != nil {
+            return fmt.Errorf("storage directory %s is not writable: %w", dir, err)
+        }
+    }
+    return nil
+}
+
+// UploadPath returns the stored location of an uploaded archive. It reads the path
+// recorded with the upload; when uploads aren't tracked it searches the upload directory.
+func (s *ZipService) UploadPath(fileID uuid.UUID) (string, error) {
+    if s.uploads != nil {
+        upload, err := s.uploads.GetByID(fileID)
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return "", ErrNotFound
+        }
+        if err != nil {
+            return "", err
+        }
+        return upload.Path, nil
+    }
+
+    matches, err := filepath.Glob(s.layout.uploadGlob(filepath.Join(s.uploadPath, "zips"), fileID))
+    if err != nil {
+        return "", err
+    }
+    if len(matches) == 0 {
+        return "", ErrNotFound
+    }
+    return matches[0], nil
+}
+
+// ProjectPath returns the directory a project's files are extracted to
+func (s *ZipService) ProjectPath(projectID uuid.UUID) string {
+    return s.layout.ProjectDir(s.extractPath, projectID)
+}
+
+// RecordUpload stores who uploaded an archive; it does nothing when uploads aren't tracked
+func (s *ZipService) RecordUpload(upload *models.FileUpload) error {
+    if s.uploads == nil {
+        return nil
+    }
+    return s.uploads.Create(upload)
+}
+
+// ListUploads returns a page of the user's uploads, newest first, along with the total
+// count. When uploads aren't tracked the list is empty.
+func (s *ZipService) ListUploads(userID uuid.UUID, limit, offset int) ([]*models.FileUpload, int64, error) {
+    if s.uploads == nil {
+        return []*models.FileUpload{}, 0, nil
+    }
+    return s.uploads.GetByUserID(userID, limit, offset)
+}
+
+// MarkExtracted records that an uploaded archive was extracted into a project
+func (s *ZipService) MarkExtracted(fileID, projectID uuid.UUID) error {
+    if s.uploads == nil {
+        return nil
+    }
+    return s.uploads.MarkExtracted(fileID, projectID)
+}
+
+// BeginExtraction marks an archive as being extracted until the returned function is
+// called. It returns ErrConflict if the archive is already being extracted.
+func (s *ZipService) BeginExtraction(zipPath string) (func(), error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if s.extracting[zipPath] {
+        return nil, fmt.Errorf("%w: ZIP file is being extracted", ErrConflict)
+    }
+    s.extracting[zipPath] = true
+
+    return func() {
+        s.mu.Lock()
+        delete(s.extracting, zipPath)
+        s.mu.Unlock()
+    }, nil
+}
+
+// DeleteUpload removes an uploaded archive and its upload record. Only the uploading user
+// may delete it; other users get ErrNotFound. Archives being extracted can't be deleted.
+func (s *ZipService) DeleteUpload(userID, fileID uuid.UUID, zipPath string) error {
+    if s.uploads != nil {
+        upload, err := s.uploads.GetByID(fileID)
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return ErrNotFound
+        }
+        if err != nil {
+            return err
+        }
+        if upload.UserID != userID {
+            return ErrNotFound
+        }
+    }
+
+    // Hold the extraction mark while deleting so an extraction can't start halfway
+    done, err := s.BeginExtraction(zipPath)
+    if err != nil {
+        return err
+    }
+    defer done()
+
+    if err := os.Remove(zipPath); err != nil {
+        if os.IsNotExist(err) {
+            return ErrNotFound
+        }
+        return err
+    }
+    s.forgetValidation(zipPath)
+
+    if s.uploads != nil {
+        return s.uploads.Delete(fileID)
+    }
+    return nil
+}
+
+// ValidateZip validates a ZIP file and returns information about its contents. Results are
+// cached until the file's size or modification time changes, so validating an unchanged
+// archive again doesn't re-walk it. Callers must not modify the returned result.
 func (s *ZipService) ValidateZip(zipPath string) (*models.ZipValidationResult, error) {
-    reader, err := zip.OpenReader(zipPath)
+    file, err := os.Open(zipPath)
+    if err != nil {
+        return &models.ZipValidationResult{
+            IsValid: false,
+            Error:   fmt.Sprintf("Failed to open ZIP file: %v", err),
+        }, nil
+    }
+    defer file.Close()
+
+    stat, err := file.Stat()
+    if err != nil {
+        return nil, err
+    }
+    if result, ok := s.cachedValidation(zipPath, stat); ok {
+        return result, nil
+    }
+
+    result, err := s.ValidateZipReader(file, stat.Size())
+    if err != nil {
+        return nil, err
+    }
+    s.cacheValidation(zipPath, stat, result)
+    return result, nil
+}
+
+// cachedValidation returns the cached validation of an archive if it hasn't changed since
+func (s *ZipService) cachedValidation(zipPath string, stat os.FileInfo) (*models.ZipValidationResult, bool) {
+    s.validationMu.Lock()
+    defer s.validationMu.Unlock()
+
+    cached, ok := s.validations[zipPath]
+    if !ok || cached.size != stat.Size() || !cached.modTime.Equal(stat.ModTime()) {
+        return nil, false
+    }
+    return cached.result, true
+}
+
+// cacheValidation remembers the validation of an archive as it is now
+func (s *ZipService) cacheValidation(zipPath string, stat os.FileInfo, result *models.ZipValidationResult) {
+    s.validationMu.Lock()
+    defer s.validationMu.Unlock()
+
+    if _, ok := s.validations[zipPath]; !ok && len(s.validations) >= maxCachedValidations {
+        s.validations = make(map[string]cachedValidation)
+    }
+    s.validations[zipPath] = cachedValidation{size: stat.Size(), modTime: stat.ModTime(), result: result}
+}
+
+// forgetValidation drops the cached validation of an archive
+func (s *ZipService) forgetValidation(zipPath string) {
+    s.validationMu.Lock()
+    delete(s.validations, zipPath)
+    s.validationMu.Unlock()
+}
+
+// CheckZipSignature sniffs the first bytes of a file for the ZIP magic number, so a file
+// merely named .zip is rejected before any parsing. Empty archives are rejected too.
+func (s *ZipService) CheckZipSignature(r io.ReaderAt) error {
+    header := make([]byte, len(zipLocalFileSignature))
+    if _, err := r.ReadAt(header, 0); err != nil {
+        if err == io.EOF {
+            return ErrNotZipArchive
+        }
+        return err
+    }
+
+    switch {
+    case bytes.Equal(header, zipLocalFileSignature):
+        return nil
+    case bytes.Equal(header, zipEmptySignature):
+        return ErrEmptyZipArchive
+    default:
+        return ErrNotZipArchive
+    }
+}
+
+// ValidateZipReader validates a ZIP archive read from r, which must hold size bytes.
+// Apart from the central directory only the first bytes of unsupported entries are read,
+// to sniff their content type, so an open upload handle can be validated without being
+// copied or looked up again by path. With VerifyCRC every entry is read in full as well.
+func (s *ZipService) ValidateZipReader(r io.ReaderAt, size int64) (*models.ZipValidationResult, error) {
+    reader, err := zip.NewReader(r, size)
     if err != nil {
         return &models.ZipValidationResult{
             IsValid: false,
             Error:   fmt.Sprintf("Failed to open ZIP file: %v", err),
         }, nil
     }
-    defer reader.Close()
 
     result := &models.ZipValidationResult{
         IsValid:          true,
         SupportedFiles:   []string{},
         UnsupportedFiles: []string{},
+
+        UnsupportedFileDetails: []models.UnsupportedFileInfo{},
     }
 
-    audioExtensions := map[string]bool{
-        ".mp3":  true,
-        ".wav":  true,
-        ".flac": true,
-        ".aac":  true,
-        ".ogg":  true,
-        ".m4a":  true,
-        ".wma":  true,
+    if len(reader.File) > s.maxEntries {
+        result.IsValid = false
+        result.TotalFiles = len(reader.File)
+        result.Error = fmt.Sprintf("ZIP file has too many entries (%d, max %d)", len(reader.File), s.maxEntries)
+        return result, nil
     }
 
     for _, file := range reader.File {
+        if s.skipHidden && isHiddenPath(file.Name) {
+            result.HiddenFiles++
+            continue
+        }
+
         result.TotalFiles++
         result.TotalSize += int64(file.UncompressedSize64)
 
@@ -74,6 +554,11 @@ func (s *ZipService) ValidateZip(zipPath string) (*models.ZipValidationResult, e
             result.SupportedFiles = append(result.SupportedFiles, file.Name)
         } else if ext != "" { // Skip files without extensions (likely directories)
             result.UnsupportedFiles = append(result.UnsupportedFiles, file.Name)
+            result.UnsupportedFileDetails = append(result.UnsupportedFileDetails, models.UnsupportedFileInfo{
+                Name:        file.Name,
+                Size:        int64(file.UncompressedSize64),
+                ContentType: sniffContentType(file, ext),
+            })
         }
     }
 
@@ -84,16 +569,154 @@ func (s *ZipService) ValidateZip(zipPath string) (*models.ZipValidationResult, e
     } else if result.AudioFiles == 0 {
         result.IsValid = false
         result.Error = "No supported audio files found in ZIP"
-    } else if result.TotalSize > 500*1024*1024 { // 500MB limit
+    } else if result.TotalSize > maxUncompressedSize {
         result.IsValid = false
         result.Error = "ZIP file is too large (max 500MB)"
     }
 
+    // Only worth reading every entry for an archive that is otherwise acceptable
+    if result.IsValid && s.verifyCRC {
+        for _, file := range reader.File {
+            if file.FileInfo().IsDir() || (s.skipHidden && isHiddenPath(file.Name)) {
+                continue
+            }
+            if err := verifyEntry(file); err != nil {
+                result.CorruptedFiles = append(result.CorruptedFiles, file.Name)
+            }
+        }
+        if len(result.CorruptedFiles) > 0 {
+            result.IsValid = false
+            result.Error = fmt.Sprintf("ZIP file is corrupted: %d entries failed the integrity check", len(result.CorruptedFiles))
+        }
+    }
+
     return result, nil
 }
 
+// verifyEntry reads an archive entry in full. archive/zip compares the content against the
+// entry's CRC32 and size once the end is reached, so truncated or damaged entries fail.
+func verifyEntry(file *zip.File) error {
+    reader, err := file.Open()
+    if err != nil {
+        return err
+    }
+    defer reader.Close()
+
+    _, err = io.Copy(io.Discard, reader)
+    return err
+}
+
+// sniffLength is how much of an entry http.DetectContentType looks at
+const sniffLength = 512
+
+// sniffContentType detects the content type of an archive entry from its first bytes.
+// Entries that can't be read or aren't recognized fall back to the type registered for
+// their extension, then to application/octet-stream.
+func sniffContentType(file *zip.File, ext string) string {
+    const unknown = "application/octet-stream"
+
+    sniffed := unknown
+    if reader, err := file.Open(); err == nil {
+        head := make([]byte, sniffLength)
+        n, _ := io.ReadFull(reader, head)
+        reader.Close()
+        if n > 0 {
+            sniffed = http.DetectContentType(head[:n])
+        }
+    }
+    if sniffed != unknown {
+        return sniffed
+    }
+
+    if byExtension := mime.TypeByExtension(ext); byExtension != "" {
+        return byExtension
+    }
+    return unknown
+}
+
+// AcquireExtractionSlot waits for one of the extraction slots shared by all requests and
+// returns the function that frees it. It gives up with ErrExtractionQueueFull once ctx is
+// done or the queue timeout passes, whichever comes first.
+func (s *ZipService) AcquireExtractionSlot(ctx context.Context) (func(), error) {
+    ctx, cancel := context.WithTimeout(ctx, s.queueTimeout)
+    defer cancel()
+
+    select {
+    case s.extractionSlots <- struct{}{}:
+        return func() { <-s.extractionSlots }, nil
+    case <-ctx.Done():
+        return nil, fmt.Errorf("%w: %v", ErrExtractionQueueFull, ctx.Err())
+    }
+}
+
 // ExtractZip extracts a ZIP file to the specified directory
 func (s *ZipService) ExtractZip(zipPath string, projectID uuid.UUID) (*models.ZipExtractionResult, error) {
+    return s.ExtractZipContext(context.Background(), zipPath, projectID)
+}
+
+// ExtractOptions changes how an archive is laid out in the project directory
+type ExtractOptions struct {
+    // StripTopLevelDir extracts the contents of an archive's single top-level directory
+    // straight into the project root, e.g. MyProject/stems/vocals.wav to stems/vocals.wav.
+    // Archives with more than one top-level entry are extracted as they are.
+    StripTopLevelDir bool
+
+    // UploadedBy is the user extracting the archive. Extracting into a saved project needs
+    // a user who may edit its files, see CheckExtractionTarget; extractions without one are
+    // the service's own. It is recorded on the File rows saved for the extracted files, the
+    // project owner when unset.
+    UploadedBy uuid.UUID
+
+    // Progress, when set, is called after each archive entry with the number of entries
+    // handled so far, extracted or skipped, and the total
+    Progress func(processed, total int)
+
+    // entry, when set, extracts only the archive entry of that name; see ExtractEntry
+    entry string
+}
+
+// ExtractZipContext extracts a ZIP file to the specified directory once an extraction slot
+// is free. Canceling ctx stops the wait for a slot or the extraction itself. Nothing is
+// written to the project directory unless every entry is extracted.
+func (s *ZipService) ExtractZipContext(ctx context.Context, zipPath string, projectID uuid.UUID) (*models.ZipExtractionResult, error) {
+    return s.ExtractZipWithOptions(ctx, zipPath, projectID, ExtractOptions{})
+}
+
+// ExtractZipWithOptions is ExtractZipContext with control over the extracted layout
+func (s *ZipService) ExtractZipWithOptions(ctx context.Context, zipPath string, projectID uuid.UUID, opts ExtractOptions) (*models.ZipExtractionResult, error) {
+    // time.Since reads the monotonic clock, so wall clock changes don't skew the timings
+    start := time.Now()
+    timings := &models.ExtractionTimings{}
+
+    if opts.UploadedBy != uuid.Nil {
+        if err := s.CheckExtractionTarget(opts.UploadedBy, projectID); err != nil {
+            return &models.ZipExtractionResult{
+                Success: false,
+                Error:   err.Error(),
+            }, err
+        }
+    }
+
+    done, err := s.BeginExtraction(zipPath)
+    if err != nil {
+        return &models.ZipExtractionResult{
+            Success: false,
+            Error:   err.Error(),
+        }, err
+    }
+    defer done()
+
+    release, err := s.AcquireExtractionSlot(ctx)
+    if err != nil {
+        return &models.ZipExtractionResult{
+            Success: false,
+            Error:   err.Error(),
+        }, err
+    }
+    defer release()
+    timings.Queue = time.Since(start)
+
+    phase := time.Now()
     reader, err := zip.OpenReader(zipPath)
     if err != nil {
         return &models.ZipExtractionResult{
@@ -102,100 +725,450 @@ func (s *ZipService) ExtractZip(zipPath string, projectID uuid.UUID) (*models.Zi
         }, err
     }
     defer reader.Close()
+    timings.Open = time.Since(phase)
 
-    extractPath := filepath.Join(s.extractPath, projectID.String())
-    if err := os.MkdirAll(extractPath, 0755); err != nil {
+    // Hard stop, even if the archive skipped validation
+    if len(reader.File) > s.maxEntries {
+        err := fmt.Errorf("ZIP file has too many entries (%d, max %d)", len(reader.File), s.maxEntries)
+        return &models.ZipExtractionResult{
+            Success: false,
+            Error:   err.Error(),
+        }, err
+    }
+
+    // Entries are written to a staging directory next to the project directory and only
+    // moved into place once all of them are extracted, so a failed or canceled extraction
+    // leaves nothing behind and can simply be retried
+    extractPath := s.ProjectPath(projectID)
+    stagingPath, err := s.createStagingDir(extractPath)
+    if err != nil {
         return &models.ZipExtractionResult{
             Success: false,
             Error:   fmt.Sprintf("Failed to create extraction directory: %v", err),
         }, err
     }
+    defer os.RemoveAll(stagingPath)
 
     result := &models.ZipExtractionResult{
         Success:        true,
         ExtractedPath:  extractPath,
         ExtractedFiles: []models.ZipFileInfo{},
         AudioFiles:     []models.ZipFileInfo{},
+        SkippedFiles:   []models.SkippedZipEntry{},
+        Timings:        timings,
     }
 
-    audioExtensions := map[string]bool{
-        ".mp3":  true,
-        ".wav":  true,
-        ".flac": true,
-        ".aac":  true,
-        ".ogg":  true,
-        ".m4a":  true,
-        ".wma":  true,
+    var prefix string
+    if opts.StripTopLevelDir {
+        prefix = singleTopLevelDir(reader.File)
+        result.StrippedPrefix = prefix
     }
 
-    for _, file := range reader.File {
-        extractedPath := filepath.Join(extractPath, file.Name)
-        
-        // Security check: prevent directory traversal
-        if !strings.HasPrefix(extractedPath, extractPath) {
+    for i, file := range reader.File {
+        if opts.Progress != nil && i > 0 {
+            opts.Progress(i, len(reader.File))
+        }
+        if opts.entry != "" && file.Name != opts.entry {
+            continue
+        }
+        phase = time.Now()
+        name := strings.TrimPrefix(file.Name, prefix)
+        if name == "" {
+            // The stripped directory itself
+            timings.Validate += time.Since(phase)
+            continue
+        }
+        if s.skipHidden && isHiddenPath(file.Name) {
+            timings.Validate += time.Since(phase)
+            result.SkippedFiles = append(result.SkippedFiles, models.SkippedZipEntry{Path: file.Name, Reason: "hidden file or directory"})
+            continue
+        }
+        extractedPath := filepath.Join(stagingPath, name)
+        if extractedPath == stagingPath {
+            // An entry for the archive root, like "./"
+            timings.Validate += time.Since(phase)
             continue
         }
 
-        fileInfo := models.ZipFileInfo{
-            Name:        filepath.Base(file.Name),
-            Path:        file.Name,
-            Size:        int64(file.UncompressedSize64),
-            IsDirectory: file.FileInfo().IsDir(),
-            ModTime:     file.FileInfo().ModTime(),
+        // Security check: prevent directory traversal. The separator keeps siblings of the
+        // staging directory, like <staging>-evil, out too.
+        if !strings.HasPrefix(extractedPath, stagingPath+string(os.PathSeparator)) {
+            timings.Validate += time.Since(phase)
+            result.SkippedFiles = append(result.SkippedFiles, models.SkippedZipEntry{Path: file.Name, Reason: "path is outside the project directory"})
+            continue
         }
 
-        if file.FileInfo().IsDir() {
-            if err := os.MkdirAll(extractedPath, file.FileInfo().Mode()); err != nil {
-                result.Error = fmt.Sprintf("Failed to create directory: %v", err)
-                continue
-            }
-        } else {
-            // Ensure parent directory exists
-            if err := os.MkdirAll(filepath.Dir(extractedPath), 0755); err != nil {
-                result.Error = fmt.Sprintf("Failed to create parent directory: %v", err)
-                continue
-            }
+        reason := s.checkPathLimits(name, extractedPath)
+        timings.Validate += time.Since(phase)
+        if reason != "" {
+            result.SkippedFiles = append(result.SkippedFiles, models.SkippedZipEntry{Path: file.Name, Reason: reason})
+            continue
+        }
 
-            // Extract file
-            if err := s.extractFile(file, extractedPath); err != nil {
-                result.Error = fmt.Sprintf("Failed to extract file %s: %v", file.Name, err)
-                continue
-            }
+        phase = time.Now()
+        err := s.extractEntryTo(ctx, file, name, extractedPath, stagingPath, result)
+        timings.Write += time.Since(phase)
+        if ctxErr := ctx.Err(); ctxErr != nil {
+            return &models.ZipExtractionResult{
+                Success: false,
+                Error:   "Extraction canceled",
+            }, ctxErr
+        }
+        if err != nil {
+            return &models.ZipExtractionResult{
+                Success: false,
+                Error:   err.Error(),
+            }, err
+        }
+    }
+    if opts.Progress != nil {
+        opts.Progress(len(reader.File), len(reader.File))
+    }
 
-            // Set file info
-            ext := strings.ToLower(filepath.Ext(file.Name))
-            fileInfo.ContentType = mime.TypeByExtension(ext)
-            fileInfo.IsAudioFile = audioExtensions[ext]
+    // The rows point at the final paths; saving them first keeps the project directory
+    // untouched if they can't be saved
+    if err := s.recordExtractedFiles(projectID, opts.UploadedBy, result); err != nil {
+        return &models.ZipExtractionResult{
+            Success: false,
+            Error:   fmt.Sprintf("Failed to save extracted files: %v", err),
+        }, err
+    }
 
-            if fileInfo.IsAudioFile {
-                result.AudioFiles = append(result.AudioFiles, fileInfo)
-            }
+    phase = time.Now()
+    err = moveExtracted(stagingPath, extractPath)
+    timings.Write += time.Since(phase)
+    if err != nil {
+        return &models.ZipExtractionResult{
+            Success: false,
+            Error:   fmt.Sprintf("Failed to move extracted files into place: %v", err),
+        }, err
+    }
+
+    timings.Total = time.Since(start)
+    return result, nil
+}
+
+// createStagingDir creates an empty directory to extract into, next to the project directory
+// so the extracted files can be renamed into it
+func (s *ZipService) createStagingDir(extractPath string) (string, error) {
+    if err := os.MkdirAll(filepath.Dir(extractPath), 0755); err != nil {
+        return "", err
+    }
+    stagingPath, err := os.MkdirTemp(filepath.Dir(extractPath), "."+filepath.Base(extractPath)+".extracting-")
+    if err != nil {
+        return "", err
+    }
+    // MkdirTemp creates it private; it becomes the project directory as it is
+    if err := os.Chmod(stagingPath, 0755); err != nil {
+        os.RemoveAll(stagingPath)
+        return "", err
+    }
+    return stagingPath, nil
+}
+
+// moveExtracted moves everything extracted to staging into the project directory dest. A
+// new project directory is renamed into place in one step; into an existing one, each file
+// is renamed over its counterpart and files the archive doesn't contain are kept.
+func moveExtracted(staging, dest string) error {
+    err := os.Rename(staging, dest)
+    if err == nil {
+        return nil
+    }
+    if _, statErr := os.Lstat(dest); statErr != nil {
+        return err
+    }
+
+    return filepath.Walk(staging, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            return err
+        }
+        relPath, err := filepath.Rel(staging, path)
+        if err != nil {
+            return err
         }
+        target := filepath.Join(dest, relPath)
+        if info.IsDir() {
+            return os.MkdirAll(target, info.Mode().Perm())
+        }
+        return os.Rename(path, target)
+    })
+}
+
+// singleTopLevelDir returns the directory, with its trailing slash, that every entry of the
+// archive sits in, or "" if the entries don't share a single top-level directory
+func singleTopLevelDir(files []*zip.File) string {
+    var prefix string
+    for _, file := range files {
+        slash := strings.Index(file.Name, "/")
+        if slash <= 0 {
+            // A file at the root, or an absolute name
+            return ""
+        }
+        dir := file.Name[:slash+1]
+        if prefix == "" {
+            prefix = dir
+        } else if dir != prefix {
+            return ""
+        }
+    }
+    if prefix == "./" || prefix == "../" {
+        return ""
+    }
+    return prefix
+}
 
-        result.ExtractedFiles = append(result.ExtractedFiles, fileInfo)
+// extractEntryTo writes one archive entry to extractedPath and records it in result under
+// name, its path relative to the project root. Entries that are skipped are recorded with
+// their name in the archive; the returned error is for entries that failed.
+func (s *ZipService) extractEntryTo(ctx context.Context, file *zip.File, name, extractedPath, extractPath string, result *models.ZipExtractionResult) error {
+    if file.Mode()&os.ModeSymlink != 0 {
+        if reason := s.extractSymlink(file, extractedPath, extractPath); reason != "" {
+            result.SkippedFiles = append(result.SkippedFiles, models.SkippedZipEntry{Path: file.Name, Reason: reason})
+            return nil
+        }
+        result.ExtractedFiles = append(result.ExtractedFiles, models.ZipFileInfo{
+            Name:    filepath.Base(name),
+            Path:    name,
+            ModTime: file.FileInfo().ModTime(),
+        })
         result.TotalFiles++
-        result.TotalSize += fileInfo.Size
+        return nil
     }
 
-    return result, nil
+    fileInfo := models.ZipFileInfo{
+        Name:        filepath.Base(name),
+        Path:        name,
+        Size:        int64(file.UncompressedSize64),
+        IsDirectory: file.FileInfo().IsDir(),
+        ModTime:     file.FileInfo().ModTime(),
+    }
+
+    if file.FileInfo().IsDir() {
+        if err := os.MkdirAll(extractedPath, file.FileInfo().Mode()); err != nil {
+            return fmt.Errorf("Failed to create directory: %v", err)
+        }
+    } else {
+        // Ensure parent directory exists
+        if err := os.MkdirAll(filepath.Dir(extractedPath), 0755); err != nil {
+            return fmt.Errorf("Failed to create parent directory: %v", err)
+        }
+
+        // Extract file
+        checksum, err := s.extractFile(ctx, file, extractedPath)
+        if err != nil {
+            return fmt.Errorf("Failed to extract file %s: %v", file.Name, err)
+        }
+        fileInfo.Checksum = checksum
+
+        // Set file info
+        ext := strings.ToLower(filepath.Ext(name))
+        fileInfo.ContentType = mime.TypeByExtension(ext)
+        fileInfo.IsAudioFile = audioExtensions[ext]
+
+        if fileInfo.IsAudioFile {
+            result.AudioFiles = append(result.AudioFiles, fileInfo)
+        }
+    }
+
+    result.ExtractedFiles = append(result.ExtractedFiles, fileInfo)
+    result.TotalFiles++
+    result.TotalSize += fileInfo.Size
+    return nil
 }
 
-// extractFile extracts a single file from ZIP
-func (s *ZipService) extractFile(file *zip.File, destPath string) error {
+// extractFile extracts a single file from ZIP and returns the hex SHA-256 of its content.
+// A file left incomplete because ctx was canceled is removed.
+func (s *ZipService) extractFile(ctx context.Context, file *zip.File, destPath string) (string, error) {
     reader, err := file.Open()
     if err != nil {
-        return err
+        return "", err
     }
     defer reader.Close()
 
     writer, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.FileInfo().Mode())
     if err != nil {
-        return err
+        return "", err
     }
     defer writer.Close()
 
-    _, err = io.Copy(writer, reader)
-    return err
+    hash := sha256.New()
+    _, err = io.Copy(io.MultiWriter(writer, hash), contextReader{ctx: ctx, reader: reader})
+    if err != nil {
+        if ctx.Err() != nil {
+            writer.Close()
+            os.Remove(destPath)
+        }
+        return "", err
+    }
+    return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// contextReader stops reading once its context is canceled
+type contextReader struct {
+    ctx    context.Context
+    reader io.Reader
+}
+
+// Read implements io.Reader
+func (r contextReader) Read(p []byte) (int, error) {
+    if err := r.ctx.Err(); err != nil {
+        return 0, err
+    }
+    return r.reader.Read(p)
+}
+
+// maxSymlinkTargetLength bounds how much of a symlink entry is read as its target
+const maxSymlinkTargetLength = 4096
+
+// extractSymlink recreates a symlink entry at destPath if symlinks are allowed and the link
+// resolves inside root. It returns why the entry was skipped, or an empty string once created.
+func (s *ZipService) extractSymlink(file *zip.File, destPath, root string) string {
+    if !s.allowSymlinks {
+        return "symbolic links are not extracted"
+    }
+
+    reader, err := file.Open()
+    if err != nil {
+        return fmt.Sprintf("failed to read link target: %v", err)
+    }
+    defer reader.Close()
+
+    target, err := io.ReadAll(io.LimitReader(reader, maxSymlinkTargetLength+1))
+    if err != nil {
+        return fmt.Sprintf("failed to read link target: %v", err)
+    }
+    if len(target) == 0 || len(target) > maxSymlinkTargetLength {
+        return "link target is empty or too long"
+    }
+
+    linkTarget := filepath.FromSlash(string(target))
+    if filepath.IsAbs(linkTarget) {
+        return "link target is outside the project"
+    }
+
+    if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+        return fmt.Sprintf("failed to create parent directory: %v", err)
+    }
+
+    // Resolve against the real parent so links created earlier can't be chained to escape
+    realRoot, err := filepath.EvalSymlinks(root)
+    if err != nil {
+        return fmt.Sprintf("failed to resolve project directory: %v", err)
+    }
+    realParent, err := filepath.EvalSymlinks(filepath.Dir(destPath))
+    if err != nil {
+        return fmt.Sprintf("failed to resolve link directory: %v", err)
+    }
+    resolved := filepath.Join(realParent, linkTarget)
+    if resolved != realRoot && !strings.HasPrefix(resolved, realRoot+string(os.PathSeparator)) {
+        return "link target is outside the project"
+    }
+
+    if err := os.Symlink(linkTarget, destPath); err != nil {
+        return fmt.Sprintf("failed to create link: %v", err)
+    }
+    return ""
+}
+
+// ExtractEntry extracts a single named entry of a ZIP file into the project directory on
+// behalf of the user, leaving the rest of the archive untouched. It goes through
+// ExtractZipWithOptions, so the same access checks, extraction slots and skipped entries
+// apply and the file is saved like any other extracted file. A new audio file must fit in
+// the project's audio file limit, or an *AudioFileLimitError is returned. It returns
+// ErrNotFound when the archive has no such file and ErrInvalid for names that would escape
+// the project directory or that extraction skips.
+func (s *ZipService) ExtractEntry(ctx context.Context, userID uuid.UUID, zipPath, entryName string, projectID uuid.UUID) (*models.ZipFileInfo, error) {
+    destPath, err := safeJoin(s.ProjectPath(projectID), entryName)
+    if err != nil {
+        return nil, err
+    }
+    if reason := s.checkPathLimits(entryName, destPath); reason != "" {
+        return nil, fmt.Errorf("%w: %s", ErrInvalid, reason)
+    }
+
+    reader, err := zip.OpenReader(zipPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open ZIP file: %w", err)
+    }
+    var entry *zip.File
+    for _, file := range reader.File {
+        if file.Name == entryName && !file.FileInfo().IsDir() {
+            entry = file
+            break
+        }
+    }
+    reader.Close()
+    if entry == nil {
+        return nil, ErrNotFound
+    }
+    if entry.Mode()&os.ModeSymlink != 0 {
+        return nil, fmt.Errorf("%w: symbolic links can't be extracted on their own", ErrInvalid)
+    }
+
+    if entry.UncompressedSize64 > maxUncompressedSize {
+        return nil, fmt.Errorf("%w: entry is too large (max 500MB)", ErrInvalid)
+    }
+
+    // An audio file counts towards the project's limit unless it replaces one already there
+    if audioExtensions[strings.ToLower(filepath.Ext(entryName))] {
+        if _, err := os.Stat(destPath); os.IsNotExist(err) {
+            if err := s.CheckAudioFileLimit(projectID, 1); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    result, err := s.ExtractZipWithOptions(ctx, zipPath, projectID, ExtractOptions{UploadedBy: userID, entry: entryName})
+    if err != nil {
+        return nil, err
+    }
+    for i := range result.ExtractedFiles {
+        if result.ExtractedFiles[i].Path == entryName {
+            return &result.ExtractedFiles[i], nil
+        }
+    }
+    if len(result.SkippedFiles) > 0 {
+        return nil, fmt.Errorf("%w: %s", ErrInvalid, result.SkippedFiles[0].Reason)
+    }
+    return nil, ErrNotFound
+}
+
+// checkPathLimits returns why an entry can't be extracted to destPath because its path is
+// too deep or too long, or an empty string when it is within the limits
+func (s *ZipService) checkPathLimits(entryName, destPath string) string {
+    depth := strings.Count(strings.Trim(entryName, "/"), "/")
+    if depth > s.maxPathDepth {
+        return fmt.Sprintf("path is nested %d directories deep (max %d)", depth, s.maxPathDepth)
+    }
+    if len(destPath) > s.maxPathLength {
+        return fmt.Sprintf("path is %d bytes long once extracted (max %d)", len(destPath), s.maxPathLength)
+    }
+    return ""
+}
+
+// isHiddenPath reports whether any component of an archive entry name starts with a dot,
+// like .git/config or stems/.DS_Store. The "." and ".." components don't count.
+func isHiddenPath(name string) bool {
+    for _, part := range strings.Split(name, "/") {
+        if strings.HasPrefix(part, ".") && part != "." && part != ".." {
+            return true
+        }
+    }
+    return false
+}
+
+// safeJoin joins an archive entry name onto base, rejecting absolute names and
+// names that resolve outside of base
+func safeJoin(base, name string) (string, error) {
+    if name == "" || filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "\\") {
+        return "", fmt.Errorf("%w: invalid entry name %q", ErrInvalid, name)
+    }
+
+    joined := filepath.Join(base, filepath.FromSlash(name))
+    if !strings.HasPrefix(joined, filepath.Clean(base)+string(os.PathSeparator)) {
+        return "", fmt.Errorf("%w: entry name %q escapes the extraction directory", ErrInvalid, name)
+    }
+    return joined, nil
 }
 
 // GetZipInfo returns information about ZIP contents without extracting
@@ -203,15 +1176,40 @@ func (s *ZipService) GetZipInfo(zipPath string) (*models.ZipValidationResult, er
     return s.ValidateZip(zipPath)
 }
 
-// CleanupExtractedFiles removes extracted files for a project
-func (s *ZipService) CleanupExtractedFiles(projectID uuid.UUID) error {
-    extractPath := filepath.Join(s.extractPath, projectID.String())
+// CleanupExtractedFiles removes extracted files for a project, for members who may edit
+// its files
+func (s *ZipService) CleanupExtractedFiles(userID, projectID uuid.UUID) error {
+    if err := s.checkCanEditFiles(userID, projectID); err != nil {
+        return err
+    }
+
+    extractPath := s.ProjectPath(projectID)
     return os.RemoveAll(extractPath)
 }
 
-// ListExtractedFiles lists all files in an extracted project directory
-func (s *ZipService) ListExtractedFiles(projectID uuid.UUID) ([]models.ZipFileInfo, error) {
-    extractPath := filepath.Join(s.extractPath, projectID.String())
+// ListExtractedFiles lists the files extracted for a project, for members of the project
+func (s *ZipService) ListExtractedFiles(userID, projectID uuid.UUID) ([]models.ZipFileInfo, error) {
+    if err := s.checkProjectAccess(userID, projectID); err != nil {
+        return nil, err
+    }
+    return s.extractedFiles(projectID)
+}
+
+// extractedFiles lists the files extracted for a project. With file storage they are
+// read from the File rows of the project's default branch, saved at extraction time, so
+// skipped entries never show up and the disk isn't walked. Projects without such rows,
+// e.g. ones extracted before files were saved, are listed from their directory instead.
+func (s *ZipService) extractedFiles(projectID uuid.UUID) ([]models.ZipFileInfo, error) {
+    files, err := s.savedExtractedFiles(projectID)
+    if err != nil || len(files) > 0 {
+        return files, err
+    }
+    return s.walkExtractedFiles(projectID)
+}
+
+// walkExtractedFiles lists all files in an extracted project directory
+func (s *ZipService) walkExtractedFiles(projectID uuid.UUID) ([]models.ZipFileInfo, error) {
+    extractPath := s.ProjectPath(projectID)
     
     var files []models.ZipFileInfo
     
@@ -242,11 +1240,7 @@ func (s *ZipService) ListExtractedFiles(projectID uuid.UUID) ([]models.ZipFileIn
         if !info.IsDir() {
             ext := strings.ToLower(filepath.Ext(info.Name()))
             fileInfo.ContentType = mime.TypeByExtension(ext)
-            
-            audioExtensions := map[string]bool{
-                ".mp3": true, ".wav": true, ".flac": true,
-                ".aac": true, ".ogg": true, ".m4a": true, ".wma": true,
-            }
+
             fileInfo.IsAudioFile = audioExtensions[ext]
         }
 
@@ -255,4 +1249,211 @@ func (s *ZipService) ListExtractedFiles(projectID uuid.UUID) ([]models.ZipFileIn
     })
 
     return files, err
-}
\ No newline at end of file
+}
+
+// GetExtractedFileInfo returns the info of one extracted file, including its checksum, for
+// members of the project. Paths that escape the project directory are ErrInvalid; missing
+// files and directories, and private projects the user isn't a member of, are ErrNotFound.
+func (s *ZipService) GetExtractedFileInfo(userID, projectID uuid.UUID, relPath string) (*models.ZipFileInfo, error) {
+    if err := s.checkProjectAccess(userID, projectID); err != nil {
+        return nil, err
+    }
+
+    projectPath := s.ProjectPath(projectID)
+    fullPath := filepath.Join(projectPath, filepath.FromSlash(relPath))
+    if relPath == "" || !strings.HasPrefix(fullPath, projectPath+string(os.PathSeparator)) {
+        return nil, fmt.Errorf("%w: path must stay inside the project", ErrInvalid)
+    }
+
+    info, err := os.Stat(fullPath)
+    if err != nil {
+        if os.IsNotExist(err) {
+            return nil, ErrNotFound
+        }
+        return nil, err
+    }
+    if info.IsDir() {
+        return nil, ErrNotFound
+    }
+
+    checksum, err := fileChecksum(fullPath)
+    if err != nil {
+        return nil, err
+    }
+
+    ext := strings.ToLower(filepath.Ext(fullPath))
+    rel, _ := filepath.Rel(projectPath, fullPath)
+    return &models.ZipFileInfo{
+        Name:        info.Name(),
+        Path:        filepath.ToSlash(rel),
+        Size:        info.Size(),
+        ContentType: mime.TypeByExtension(ext),
+        IsAudioFile: audioExtensions[ext],
+        ModTime:     info.ModTime(),
+        Checksum:    checksum,
+    }, nil
+}
+
+// CheckExtractionTarget returns nil if the user may extract into the project. A project
+// that isn't saved, like the one a new archive is extracted into, has no members yet and is
+// open to the user; a saved one needs a member who may edit its files. Without project
+// storage no project is saved.
+func (s *ZipService) CheckExtractionTarget(userID, projectID uuid.UUID) error {
+    if s.policy == nil {
+        return nil
+    }
+
+    project, err := s.projects.GetByID(projectID)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return s.policy.CanEditFiles(userID, project)
+}
+
+// checkProjectAccess returns nil if the user may read the project's files
+func (s *ZipService) checkProjectAccess(userID, projectID uuid.UUID) error {
+    project, err := s.getProject(projectID)
+    if err != nil {
+        return err
+    }
+    return s.policy.CanAccessProject(userID, project)
+}
+
+// checkCanEditFiles returns nil if the user may change the project's files
+func (s *ZipService) checkCanEditFiles(userID, projectID uuid.UUID) error {
+    project, err := s.getProject(projectID)
+    if err != nil {
+        return err
+    }
+    return s.policy.CanEditFiles(userID, project)
+}
+
+// getProject loads a saved project, returning ErrNotFound if there is none
+func (s *ZipService) getProject(projectID uuid.UUID) (*models.Project, error) {
+    if s.policy == nil {
+        return nil, errors.New("zip service has no project repository")
+    }
+
+    project, err := s.projects.GetByID(projectID)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, ErrNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return project, nil
+}
+
+// GetFilesMetadata returns metadata for a batch of extracted audio files, keyed by the requested
+// relative path, for members of the project. The stored audio metadata of the default
+// branch's file is returned when there is one, otherwise it is read from the file. Paths that
+// escape the project directory, do not exist or are not audio files are skipped.
+func (s *ZipService) GetFilesMetadata(userID, projectID uuid.UUID, paths []string) (map[string]models.ExtractedFileMetadata, error) {
+    if len(paths) > MaxMetadataBatchSize {
+        return nil, fmt.Errorf("%w: at most %d files per request", ErrInvalid, MaxMetadataBatchSize)
+    }
+    if err := s.checkProjectAccess(userID, projectID); err != nil {
+        return nil, err
+    }
+
+    projectPath := s.ProjectPath(projectID)
+    if _, err := os.Stat(projectPath); err != nil {
+        if os.IsNotExist(err) {
+            return nil, ErrNotFound
+        }
+        return nil, err
+    }
+
+    stored, err := s.savedAudioMetadata(projectID)
+    if err != nil {
+        return nil, err
+    }
+
+    result := make(map[string]models.ExtractedFileMetadata, len(paths))
+    for _, relPath := range paths {
+        if _, seen := result[relPath]; seen {
+            continue
+        }
+
+        fullPath := filepath.Join(projectPath, filepath.FromSlash(relPath))
+        if !strings.HasPrefix(fullPath, projectPath+string(os.PathSeparator)) {
+            continue
+        }
+
+        ext := strings.ToLower(filepath.Ext(fullPath))
+        if !audioExtensions[ext] {
+            continue
+        }
+
+        info, err := os.Stat(fullPath)
+        if err != nil || info.IsDir() {
+            continue
+        }
+
+        checksum, err := fileChecksum(fullPath)
+        if err != nil {
+            return nil, err
+        }
+
+        metadata, ok := stored[filepath.ToSlash(filepath.Clean(filepath.FromSlash(relPath)))]
+        if !ok {
+            metadata = newAudioMetadata(uuid.Nil, readAudioInfo(fullPath))
+        }
+
+        result[relPath] = models.ExtractedFileMetadata{
+            Path:          relPath,
+            Size:          info.Size(),
+            Checksum:      checksum,
+            ContentType:   mime.TypeByExtension(ext),
+            AudioMetadata: metadata,
+        }
+    }
+
+    return result, nil
+}
+
+// savedAudioMetadata returns the stored audio metadata of the files of a project's default
+// branch, keyed by path. It returns nothing without file storage or a default branch.
+func (s *ZipService) savedAudioMetadata(projectID uuid.UUID) (map[string]*models.AudioMetadata, error) {
+    if s.branches == nil || s.files == nil {
+        return nil, nil
+    }
+
+    branch, err := s.defaultBranch(projectID)
+    if errors.Is(err, ErrNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    files, err := s.files.GetByBranchID(branch.ID)
+    if err != nil {
+        return nil, err
+    }
+
+    metadata := make(map[string]*models.AudioMetadata, len(files))
+    for _, file := range files {
+        if file.AudioMetadata != nil {
+            metadata[filepath.ToSlash(file.Path)] = file.AudioMetadata
+        }
+    }
+    return metadata, nil
+}
+
+// fileChecksum returns the hex-encoded SHA-256 of a file
+func fileChecksum(path string) (string, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer file.Close()
+
+    hash := sha256.New()
+    if _, err := io.Copy(hash, file); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Page sizes shared by the list endpoints
+const (
+    DefaultPageSize = 20
+    MaxPageSize     = 100 // largest page unless SetMaxPageSize changes it
+)
+
+// maxPageSize is the largest page any list endpoint returns
+var maxPageSize = MaxPageSize
+
+// SetMaxPageSize changes the largest page any list endpoint returns; values below 1 are
+// ignored. It is meant to be called during initialization, before requests are served.
+func SetMaxPageSize(size int) {
+    if size > 0 {
+        maxPageSize = size
+    }
+}
+
+// Pagination is a parsed page request. Page is 1-based; Offset is derived from it.
+type Pagination struct {
+    Page   int
+    Limit  int
+    Offset int
+}
+
+// ParsePaginationParams reads the page and limit query parameters with the default page sizes.
+// See ParsePagination for how the values are interpreted.
+func ParsePaginationParams(c *gin.Context) Pagination {
+    return ParsePagination(c, DefaultPageSize)
+}
+
+// ParsePagination reads the page and limit query parameters. A missing or invalid limit
+// falls back to defaultLimit and every limit is clamped to the maximum page size, so the
+// returned Limit is what the response should report. Pages start at 1. Without a page,
+// the older offset parameter is still honoured.
+func ParsePagination(c *gin.Context, defaultLimit int) Pagination {
+    limit := defaultLimit
+    if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+        limit = l
+    }
+    if limit > maxPageSize {
+        limit = maxPageSize
+    }
+
+    if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+        return Pagination{Page: p, Limit: limit, Offset: (p - 1) * limit}
+    }
+
+    offset := 0
+    if o, err := strconv.Atoi(c.Query("offset")); err == nil && o > 0 {
+        offset = o
+    }
+    return Pagination{Page: offset/limit + 1, Limit: limit, Offset: offset}
+}
@@ -1,49 +1,88 @@
 package utils
 
-// APIResponse represents a successful API response
-type APIResponse struct {
-    Status  string      `json:"status" example:"success"`
-    Data    interface{} `json:"data"`
-    Message string      `json:"message,omitempty"`
+import (
+    "errors"
+    "net/http"
+
+    "collabhub-music-backend/internal/middleware"
+    "collabhub-music-backend/internal/services"
+    pkgutils "collabhub-music-backend/pkg/utils"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Response represents the standard API response envelope
+type Response struct {
+    Success bool        `json:"success"`
+    Message string      `json:"message"`
+    Data    interface{} `json:"data,omitempty"`
+    Error   string      `json:"error,omitempty"`
+
+    // Set on error responses only
+    ErrorCode pkgutils.ErrorCode `json:"error_code,omitempty"`
+    Errors    interface{}        `json:"errors,omitempty"`
 }
 
-// APIError represents an error API response
-type APIError struct {
-    Status  string `json:"status" example:"error"`
-    Error   string `json:"error" example:"Something went wrong"`
-    Code    int    `json:"code,omitempty" example:"400"`
+// SuccessResponse writes a successful response with the given status code
+func SuccessResponse(c *gin.Context, status int, message string, data interface{}) {
+    c.JSON(status, Response{
+        Success: true,
+        Message: message,
+        Data:    data,
+    })
 }
 
-// SuccessResponse creates a success response
-func SuccessResponse(data interface{}) APIResponse {
-    return APIResponse{
-        Status: "success",
-        Data:   data,
+// ErrorResponse writes an error response with the given status code
+func ErrorResponse(c *gin.Context, status int, message string, err error) {
+    response := Response{
+        Success:   false,
+        Message:   message,
+        ErrorCode: pkgutils.ErrorCodeForStatus(status),
+    }
+    if err != nil {
+        response.Error = err.Error()
     }
+    c.JSON(status, response)
 }
 
-// SuccessResponseWithMessage creates a success response with message
-func SuccessResponseWithMessage(data interface{}, message string) APIResponse {
-    return APIResponse{
-        Status:  "success",
-        Data:    data,
-        Message: message,
-    }
+// ValidationErrorResponse writes a 422 response listing the fields that failed validation
+func ValidationErrorResponse(c *gin.Context, message string, errs interface{}) {
+    c.JSON(http.StatusUnprocessableEntity, Response{
+        Success:   false,
+        Message:   message,
+        ErrorCode: pkgutils.ErrCodeValidation,
+        Errors:    errs,
+    })
 }
 
-// ErrorResponse creates an error response
-func ErrorResponse(message string) APIError {
-    return APIError{
-        Status: "error",
-        Error:  message,
+// BindJSON binds the JSON request body into obj. Invalid fields get a 422 listing them by
+// JSON key and malformed bodies a 400; it reports false once it has responded.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+    err := c.ShouldBindJSON(obj)
+    if err == nil {
+        return true
     }
+
+    if fieldErrors := middleware.BindingErrors(c, err); fieldErrors != nil {
+        ValidationErrorResponse(c, "Validation failed", fieldErrors)
+    } else {
+        ErrorResponse(c, http.StatusBadRequest, "Request body is not valid JSON", nil)
+    }
+    return false
 }
 
-// ErrorResponseWithCode creates an error response with status code
-func ErrorResponseWithCode(message string, code int) APIError {
-    return APIError{
-        Status: "error",
-        Error:  message,
-        Code:   code,
+// HandleServiceError maps a service error to the matching HTTP error response
+func HandleServiceError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrNotFound):
+        ErrorResponse(c, http.StatusNotFound, "Resource not found", err)
+    case errors.Is(err, services.ErrForbidden):
+        ErrorResponse(c, http.StatusForbidden, "Insufficient permissions", err)
+    case errors.Is(err, services.ErrInvalid):
+        ErrorResponse(c, http.StatusBadRequest, "Invalid request", err)
+    case errors.Is(err, services.ErrConflict):
+        ErrorResponse(c, http.StatusConflict, "Resource is busy", err)
+    default:
+        ErrorResponse(c, http.StatusInternalServerError, "Internal server error", nil)
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// contextKey is the type of the request fields stored in a context
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+	userIDKey    contextKey = "user_id"
+	routeKey     contextKey = "route"
+)
+
+// contextFields lists the fields FromContext copies onto log entries, in order
+var contextFields = []contextKey{requestIDKey, userIDKey, routeKey}
+
+// WithRequestID returns a copy of ctx carrying the request ID for logging
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID for logging
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// WithRoute returns a copy of ctx carrying the matched route for logging
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// FromContext returns a log entry of the global logger carrying the request_id,
+// user_id and route stored in ctx. Fields missing from ctx are left out.
+func FromContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(Logger)
+	if ctx == nil {
+		return entry
+	}
+
+	fields := logrus.Fields{}
+	for _, key := range contextFields {
+		if value, ok := ctx.Value(key).(string); ok && value != "" {
+			fields[string(key)] = value
+		}
+	}
+	if len(fields) == 0 {
+		return entry
+	}
+	return entry.WithFields(fields)
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,33 +16,70 @@ func init() {
 	// Set output to stdout
 	Logger.SetOutput(os.Stdout)
 
-	// Set default log level
-	Logger.SetLevel(logrus.InfoLevel)
-
-	// Use JSON formatter for structured logging
-	Logger.SetFormatter(&logrus.JSONFormatter{})
+	// Default to info level and JSON for structured logging, unless overridden
+	// by LOG_LEVEL and LOG_FORMAT
+	Logger.SetLevel(levelFromEnv(logrus.InfoLevel))
+	Logger.SetFormatter(formatterFromEnv(&logrus.JSONFormatter{}))
 }
 
-// NewLogger creates a new logger instance with custom configuration
+// NewLogger creates a new logger instance with custom configuration.
+// Defaults depend on SERVER_ENV; LOG_LEVEL and LOG_FORMAT override them.
 func NewLogger() *logrus.Logger {
 	logger := logrus.New()
 
 	// Configure based on environment
 	env := os.Getenv("SERVER_ENV")
 	if env == "production" {
-		logger.SetLevel(logrus.WarnLevel)
-		logger.SetFormatter(&logrus.JSONFormatter{})
+		logger.SetLevel(levelFromEnv(logrus.WarnLevel))
+		logger.SetFormatter(formatterFromEnv(&logrus.JSONFormatter{}))
 	} else {
-		logger.SetLevel(logrus.DebugLevel)
-		logger.SetFormatter(&logrus.TextFormatter{
+		logger.SetLevel(levelFromEnv(logrus.DebugLevel))
+		logger.SetFormatter(formatterFromEnv(&logrus.TextFormatter{
 			FullTimestamp: true,
 			ForceColors:   true,
-		})
+		}))
 	}
 
 	return logger
 }
 
+// SetLevel changes the level of the global logger at runtime, e.g. "debug" or "warn".
+// The current level is kept if the value isn't a valid logrus level.
+func SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(strings.TrimSpace(level))
+	if err != nil {
+		return err
+	}
+	Logger.SetLevel(parsed)
+	return nil
+}
+
+// levelFromEnv returns the level set in LOG_LEVEL, or the fallback when it is unset or invalid
+func levelFromEnv(fallback logrus.Level) logrus.Level {
+	value := strings.TrimSpace(os.Getenv("LOG_LEVEL"))
+	if value == "" {
+		return fallback
+	}
+	level, err := logrus.ParseLevel(value)
+	if err != nil {
+		return fallback
+	}
+	return level
+}
+
+// formatterFromEnv returns the formatter selected by LOG_FORMAT ("json" or "text"),
+// or the fallback when it is unset or unknown
+func formatterFromEnv(fallback logrus.Formatter) logrus.Formatter {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))) {
+	case "json":
+		return &logrus.JSONFormatter{}
+	case "text":
+		return &logrus.TextFormatter{FullTimestamp: true}
+	default:
+		return fallback
+	}
+}
+
 // Info logs an info message
 func Info(args ...interface{}) {
 	Logger.Info(args...)
@@ -0,0 +1,92 @@
+package utils
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a kind of error. Clients should
+// branch on it rather than on the human-readable message, which may change.
+type ErrorCode string
+
+// General error codes, one per HTTP status the API returns
+const (
+    ErrCodeBadRequest         ErrorCode = "BAD_REQUEST"
+    ErrCodeValidation         ErrorCode = "VALIDATION_ERROR"
+    ErrCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+    ErrCodeForbidden          ErrorCode = "FORBIDDEN"
+    ErrCodeNotFound           ErrorCode = "NOT_FOUND"
+    ErrCodeConflict           ErrorCode = "CONFLICT"
+    ErrCodePayloadTooLarge    ErrorCode = "PAYLOAD_TOO_LARGE"
+    ErrCodeRateLimited        ErrorCode = "RATE_LIMITED"
+    ErrCodeInternal           ErrorCode = "INTERNAL_ERROR"
+    ErrCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+)
+
+// Specific error codes for failures clients are expected to handle on their own
+const (
+    ErrCodeAccountInactive     ErrorCode = "ACCOUNT_INACTIVE"
+    ErrCodeAudioFileLimit      ErrorCode = "AUDIO_FILE_LIMIT_EXCEEDED"
+    ErrCodeExtractionQueueFull ErrorCode = "EXTRACTION_QUEUE_FULL"
+    ErrCodeExtractionRunning   ErrorCode = "EXTRACTION_IN_PROGRESS"
+    ErrCodeInvalidArchive      ErrorCode = "INVALID_ARCHIVE"
+)
+
+// statusErrorCodes maps HTTP statuses to their general error code
+var statusErrorCodes = map[int]ErrorCode{
+    http.StatusBadRequest:            ErrCodeBadRequest,
+    http.StatusUnauthorized:          ErrCodeUnauthorized,
+    http.StatusForbidden:             ErrCodeForbidden,
+    http.StatusNotFound:              ErrCodeNotFound,
+    http.StatusConflict:              ErrCodeConflict,
+    http.StatusRequestEntityTooLarge: ErrCodePayloadTooLarge,
+    http.StatusUnprocessableEntity:   ErrCodeValidation,
+    http.StatusTooManyRequests:       ErrCodeRateLimited,
+    http.StatusInternalServerError:   ErrCodeInternal,
+    http.StatusServiceUnavailable:    ErrCodeServiceUnavailable,
+}
+
+// ErrorCodeForStatus returns the general error code of an HTTP status. Unlisted 4xx
+// statuses are BAD_REQUEST and everything else INTERNAL_ERROR.
+func ErrorCodeForStatus(status int) ErrorCode {
+    if code, ok := statusErrorCodes[status]; ok {
+        return code
+    }
+    if status >= 400 && status < 500 {
+        return ErrCodeBadRequest
+    }
+    return ErrCodeInternal
+}
+
+// NewError creates an error response for the HTTP status with the status's general code
+func NewError(status int, message string) APIError {
+    return NewErrorWithCode(status, ErrorCodeForStatus(status), message)
+}
+
+// NewErrorWithCode creates an error response with a specific error code
+func NewErrorWithCode(status int, code ErrorCode, message string) APIError {
+    return APIError{
+        Status:    "error",
+        Error:     message,
+        Code:      status,
+        ErrorCode: code,
+    }
+}
+
+// RespondError writes an error response for the HTTP status with the status's general code
+func RespondError(c *gin.Context, status int, message string) {
+    c.JSON(status, NewError(status, message))
+}
+
+// RespondValidationError writes a 422 response listing the fields that failed validation
+func RespondValidationError(c *gin.Context, message string, errs interface{}) {
+    response := NewError(http.StatusUnprocessableEntity, message)
+    response.Errors = errs
+    c.JSON(http.StatusUnprocessableEntity, response)
+}
+
+// RespondErrorWithCode writes an error response with a specific error code
+func RespondErrorWithCode(c *gin.Context, status int, code ErrorCode, message string) {
+    c.JSON(status, NewErrorWithCode(status, code, message))
+}
@@ -1,17 +1,37 @@
 package utils
 
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
 // APIResponse represents a successful API response
 type APIResponse struct {
-    Status  string      `json:"status" example:"success"`
-    Data    interface{} `json:"data"`
-    Message string      `json:"message,omitempty"`
+    Status     string      `json:"status" example:"success"`
+    Data       interface{} `json:"data"`
+    Message    string      `json:"message,omitempty"`
+    Pagination *Pagination `json:"pagination,omitempty"`
 }
 
-// APIError represents an error API response
+// Pagination describes the page of a list returned in an API response
+type Pagination struct {
+    Limit   int  `json:"limit" example:"100"`
+    Offset  int  `json:"offset" example:"0"`
+    Total   int  `json:"total" example:"250"`
+    HasMore bool `json:"has_more" example:"true"`
+}
+
+// APIError represents an error API response. Error is meant for people; ErrorCode is the
+// stable code from the catalog in error_codes.go that clients should check.
 type APIError struct {
-    Status  string `json:"status" example:"error"`
-    Error   string `json:"error" example:"Something went wrong"`
-    Code    int    `json:"code,omitempty" example:"400"`
+    Status    string    `json:"status" example:"error"`
+    Error     string    `json:"error" example:"Something went wrong"`
+    Code      int       `json:"code" example:"400"`
+    ErrorCode ErrorCode `json:"error_code" example:"BAD_REQUEST"`
+
+    // Errors lists the invalid fields of a VALIDATION_ERROR response
+    Errors interface{} `json:"errors,omitempty"`
 }
 
 // SuccessResponse creates a success response
@@ -31,19 +51,21 @@ func SuccessResponseWithMessage(data interface{}, message string) APIResponse {
     }
 }
 
-// ErrorResponse creates an error response
-func ErrorResponse(message string) APIError {
-    return APIError{
-        Status: "error",
-        Error:  message,
+// PaginatedResponse creates a success response for one page of a list
+func PaginatedResponse(data interface{}, limit, offset, total int) APIResponse {
+    return APIResponse{
+        Status: "success",
+        Data:   data,
+        Pagination: &Pagination{
+            Limit:   limit,
+            Offset:  offset,
+            Total:   total,
+            HasMore: offset+limit < total,
+        },
     }
 }
 
-// ErrorResponseWithCode creates an error response with status code
-func ErrorResponseWithCode(message string, code int) APIError {
-    return APIError{
-        Status: "error",
-        Error:  message,
-        Code:   code,
-    }
-}
\ No newline at end of file
+// UnauthorizedResponse writes a 401 error response
+func UnauthorizedResponse(c *gin.Context, message string) {
+    RespondError(c, http.StatusUnauthorized, message)
+}